@@ -0,0 +1,477 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zibilal/structiterator/validator"
+)
+
+const validatorImport = "github.com/zibilal/structiterator/validator"
+
+// Generator writes Validate methods for the struct types of one package
+type Generator struct {
+	pkgName string
+	structs map[string]*ast.StructType
+	names   []string
+	ruled   map[string]bool
+	// validated are the types of the package declaring their own Validate() error method
+	validated map[string]bool
+
+	buf        bytes.Buffer
+	calls      bool
+	queued     map[string]bool
+	queue      []string
+	useStrings bool
+}
+
+// NewGenerator parses the go files in dir, skipping test files and the file named output
+func NewGenerator(dir, output string) (*Generator, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go") && info.Name() != output
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected one package in %s, found %d", dir, len(pkgs))
+	}
+
+	g := &Generator{
+		structs:   make(map[string]*ast.StructType),
+		ruled:     make(map[string]bool),
+		validated: make(map[string]bool),
+	}
+	for name, pkg := range pkgs {
+		g.pkgName = name
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				if fn, ok := decl.(*ast.FuncDecl); ok {
+					if name := validateReceiver(fn); name != "" {
+						g.validated[name] = true
+					}
+					continue
+				}
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					if st, ok := ts.Type.(*ast.StructType); ok {
+						g.structs[ts.Name.Name] = st
+						g.names = append(g.names, ts.Name.Name)
+					}
+				}
+			}
+		}
+	}
+	sort.Strings(g.names)
+
+	for _, name := range g.names {
+		g.ruled[name] = g.hasRules(g.structs[name], map[string]bool{name: true})
+	}
+
+	return g, nil
+}
+
+// hasRules reports whether st or a struct walked from st holds a valid tag
+func (g *Generator) hasRules(st *ast.StructType, visiting map[string]bool) bool {
+	for _, field := range st.Fields.List {
+		if fieldTag(field).Get("valid") != "" {
+			return true
+		}
+		if name, _ := g.validatedType(field.Type); name != "" {
+			return true
+		}
+		if nested, name := g.nestedStruct(field.Type); nested != nil && !visiting[name] {
+			if name != "" {
+				visiting[name] = true
+			}
+			if g.hasRules(nested, visiting) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Generate writes the methods of types, or of every struct type holding valid tag when types is empty.
+// Nested struct types of the package are generated too, their methods are called by the outer type.
+func (g *Generator) Generate(types []string) ([]byte, error) {
+	if len(types) == 0 {
+		for _, name := range g.names {
+			if g.ruled[name] && !g.validated[name] {
+				types = append(types, name)
+			}
+		}
+	}
+	for _, name := range types {
+		if g.validated[name] {
+			return nil, fmt.Errorf("type %s declares its own Validate method", name)
+		}
+	}
+
+	g.queued = make(map[string]bool)
+	g.queue = nil
+	g.useStrings = false
+	for _, name := range types {
+		g.enqueue(name)
+	}
+
+	var body bytes.Buffer
+	for i := 0; i < len(g.queue); i++ {
+		name := g.queue[i]
+		st, found := g.structs[name]
+		if !found {
+			return nil, fmt.Errorf("struct type %s is not found in package %s", name, g.pkgName)
+		}
+
+		g.buf.Reset()
+		g.calls = false
+		if err := g.writeStruct("v", "", st); err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err.Error())
+		}
+
+		if !g.validated[name] {
+			fmt.Fprintf(&body, "\n// Validate checks %s against its valid tags, it reports the same errors as validator.ValidStruct.Valid\n", name)
+			fmt.Fprintf(&body, "func (v %s) Validate() error {\n\treturn validator.ErrorsOrNil(v.validationErrors(\"\"))\n}\n", name)
+		}
+		fmt.Fprintf(&body, "\nfunc (v %s) validationErrors(prefix string) []error {\n", name)
+		body.WriteString("\tvar errs []error\n")
+		if g.calls {
+			body.WriteString("\tvar validation validator.Validation\n")
+		}
+		body.WriteString("\n")
+		body.Write(g.buf.Bytes())
+		body.WriteString("\n\treturn errs\n}\n")
+	}
+
+	var out bytes.Buffer
+	out.WriteString("// Code generated by structvalidgen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&out, "package %s\n\nimport (\n", g.pkgName)
+	if g.useStrings {
+		out.WriteString("\t\"strings\"\n")
+	}
+	fmt.Fprintf(&out, "\n\t%q\n)\n", validatorImport)
+	out.Write(body.Bytes())
+
+	return format.Source(out.Bytes())
+}
+
+// nestedStruct returns the struct walked for a field of type expr, like ValidStruct.Valid walks nested structs.
+// name is the type name of a struct declared in the package, or empty for an inline struct.
+// Struct types of other packages are not walked, their fields are not known without type checking.
+func (g *Generator) nestedStruct(expr ast.Expr) (*ast.StructType, string) {
+	switch t := expr.(type) {
+	case *ast.StructType:
+		return t, ""
+	case *ast.Ident:
+		if st, found := g.structs[t.Name]; found {
+			return st, t.Name
+		}
+	}
+	return nil, ""
+}
+
+// writeStruct writes the checks of the fields of st, recv is the expression of the struct value
+// and path the field path of the struct from the generated method, added to its prefix argument
+func (g *Generator) writeStruct(recv, path string, st *ast.StructType) error {
+	for _, field := range st.Fields.List {
+		names := make([]string, 0, len(field.Names))
+		for _, name := range field.Names {
+			names = append(names, name.Name)
+		}
+		embedded := len(names) == 0
+		if embedded {
+			names = append(names, embeddedName(field.Type))
+		}
+
+		tag := fieldTag(field)
+		for _, name := range names {
+			if !ast.IsExported(name) && !embedded {
+				continue // unexported field
+			}
+			access := recv + "." + name
+			fieldPath := path + fieldKey(name, tag)
+			nestedPath := fieldPath + "."
+			if embedded {
+				nestedPath = path
+			}
+
+			start := g.buf.Len()
+			if typeName, pointer := g.validatedType(field.Type); typeName != "" {
+				if !embedded {
+					if err := g.writeRules(recv, access, fieldPath, name, tag); err != nil {
+						return fmt.Errorf("%s: %s", name, err.Error())
+					}
+				}
+				validatePath := fieldPath
+				if embedded {
+					validatePath = nestedPath
+				}
+				g.writeValidate(access, validatePath, embedded, pointer)
+				g.omitEmpty(access, tag, start)
+				continue
+			}
+
+			if nested, typeName := g.nestedStruct(field.Type); nested != nil {
+				if err := g.writeNested(access, nestedPath, nested, typeName); err != nil {
+					return err
+				}
+				continue
+			}
+
+			if err := g.writeRules(recv, access, fieldPath, name, tag); err != nil {
+				return fmt.Errorf("%s: %s", name, err.Error())
+			}
+			g.omitEmpty(access, tag, start)
+
+			if star, ok := field.Type.(*ast.StarExpr); ok {
+				if nested, typeName := g.nestedStruct(star.X); nested != nil {
+					fmt.Fprintf(&g.buf, "\tif %s != nil {\n", access)
+					if err := g.writeNested(access, nestedPath, nested, typeName); err != nil {
+						return err
+					}
+					g.buf.WriteString("\t}\n")
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func (g *Generator) writeNested(access, path string, nested *ast.StructType, typeName string) error {
+	if typeName == "" {
+		return g.writeStruct(access, path, nested)
+	}
+	if g.ruled[typeName] {
+		g.enqueue(typeName)
+		fmt.Fprintf(&g.buf, "\terrs = append(errs, %s.validationErrors(%s)...)\n", access, pathExpr(path))
+	}
+	return nil
+}
+
+// writeValidate writes the call of the Validate method of a field whose type declares it, ValidStruct.Valid
+// calls it the same way instead of walking the type. path of an embedded type is the path of the outer struct.
+func (g *Generator) writeValidate(access, path string, embedded, pointer bool) {
+	expr := pathExpr(path)
+	if embedded {
+		g.useStrings = true
+		expr = fmt.Sprintf("strings.TrimSuffix(%s, \".\")", expr)
+	}
+
+	call := fmt.Sprintf("\terrs = append(errs, validator.FieldErrors(%s, %s.Validate())...)\n", expr, access)
+	if pointer {
+		call = fmt.Sprintf("\tif %s != nil {\n\t%s\t}\n", access, call)
+	}
+	g.buf.WriteString(call)
+}
+
+// omitEmpty wraps the checks written from start in a check of the value of access, when tag has the omitempty option
+func (g *Generator) omitEmpty(access string, tag reflect.StructTag, start int) {
+	if !validator.IsOmitEmpty(tag.Get("valid")) || g.buf.Len() == start {
+		return
+	}
+
+	checks := append([]byte(nil), g.buf.Bytes()[start:]...)
+	g.buf.Truncate(start)
+	fmt.Fprintf(&g.buf, "\tif !validator.IsUnset(%s) {\n", access)
+	g.buf.Write(checks)
+	g.buf.WriteString("\t}\n")
+}
+
+// validatedType returns the name of the package type of a field of type expr declaring its own Validate method,
+// and whether the field is a pointer to it. A struct type with valid tag on its fields is walked instead, like
+// ValidStruct.Valid does, and its method is not called.
+func (g *Generator) validatedType(expr ast.Expr) (string, bool) {
+	pointer := false
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr, pointer = star.X, true
+	}
+	if ident, ok := expr.(*ast.Ident); ok && g.validated[ident.Name] && !g.hasFieldTags(ident.Name) {
+		return ident.Name, pointer
+	}
+	return "", false
+}
+
+// hasFieldTags reports whether a field of the struct type name has valid tag
+func (g *Generator) hasFieldTags(name string) bool {
+	st, found := g.structs[name]
+	for i := 0; found && i < len(st.Fields.List); i++ {
+		if fieldTag(st.Fields.List[i]).Get("valid") != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (g *Generator) enqueue(name string) {
+	if !g.queued[name] {
+		g.queued[name] = true
+		g.queue = append(g.queue, name)
+	}
+}
+
+var validationType = reflect.TypeOf(validator.Validation{})
+
+// writeRules writes the funcVal calls of one field, dispatched the same way ValidStruct.Valid does by the funcVal signature
+func (g *Generator) writeRules(recv, access, path, name string, tag reflect.StructTag) error {
+	keyName := fieldKey(name, tag)
+	sensitive := validator.IsSensitive(tag.Get("valid"))
+
+	for _, rule := range validator.ParseRules(tag.Get("valid")) {
+		if rule.Expr != "" {
+			return fmt.Errorf("expr rule %s is evaluated by ValidStruct and is not supported", rule.Expr)
+		}
+		if rule.FuncVal == "" {
+			continue
+		}
+
+		if validator.IsRuleExpr(rule.FuncVal) {
+			return fmt.Errorf("rule expression %s is evaluated by ValidStruct and is not supported", rule.FuncVal)
+		}
+
+		if validator.IsStructRule(rule.FuncVal) {
+			return fmt.Errorf("funcVal %s runs with the state of a ValidStruct and is not supported", rule.FuncVal)
+		}
+
+		method, found := validationType.MethodByName(rule.FuncVal)
+		if !found {
+			return fmt.Errorf("funcVal %s is not a method of validator.Validation, custom validators are not supported", rule.FuncVal)
+		}
+
+		if rule.FuncVal == "Match" && strings.HasPrefix(rule.Format, "@") {
+			return fmt.Errorf("named pattern %s is registered at runtime and is not supported", rule.Format)
+		}
+
+		message, renderValue := g.message(rule, keyName, sensitive)
+		var args []string
+		switch method.Type.NumIn() - 1 {
+		case 3:
+			args = []string{access, strconv.Quote(keyName), message}
+		case 4:
+			switch {
+			case rule.CompareKey != "" && rule.CompareValue != "":
+				args = []string{recv, strconv.Quote(rule.CompareKey), strconv.Quote(rule.CompareValue), message}
+			case rule.CompareKey != "":
+				args = []string{recv, strconv.Quote(keyName), strconv.Quote(rule.CompareKey), message}
+			case rule.Values != "":
+				args = []string{access, strconv.Quote(keyName), strconv.Quote(rule.Values), message}
+			case rule.Format != "":
+				args = []string{access, strconv.Quote(keyName), strconv.Quote(rule.Format), message}
+			}
+		case 5:
+			if rule.Format != "" && rule.DateLayout != "" {
+				args = []string{access, strconv.Quote(keyName), strconv.Quote(rule.Format), strconv.Quote(rule.DateLayout), message}
+			} else {
+				args = []string{access, strconv.Quote(keyName), "validator.DateFormat", "validator.DateLayout", message}
+			}
+		case 6:
+			if rule.CompareKey != "" && rule.CompareValue != "" {
+				args = []string{recv, strconv.Quote(keyName), access, strconv.Quote(rule.CompareKey), strconv.Quote(rule.CompareValue), message}
+			}
+		}
+
+		if args == nil {
+			continue // ValidStruct.Valid skips a funcVal missing its attributes
+		}
+
+		errExpr := "err"
+		if renderValue {
+			errExpr = fmt.Sprintf("validator.RenderValue(err, %s)", access)
+		}
+
+		g.calls = true
+		fmt.Fprintf(&g.buf, "\tif err := validation.%s(%s); err != nil {\n\t\terrs = append(errs, &validator.FieldError{Field: %s, Rule: %q, Err: %s})\n\t}\n",
+			rule.FuncVal, strings.Join(args, ", "), pathExpr(path), rule.FuncVal, errExpr)
+	}
+
+	return nil
+}
+
+// message returns the error message of rule, rendered the same way ValidStruct.Valid renders errorMessage,
+// and whether it holds {value}, rendered with validator.RenderValue once the rule failed.
+// A sensitive field gets validator.SensitiveMessage and never its value.
+func (g *Generator) message(rule validator.Rule, keyName string, sensitive bool) (string, bool) {
+	if rule.ErrorMessage == "" && !sensitive {
+		return `""`, false
+	}
+
+	params := validator.RuleMessageParams(rule, keyName, validator.DateFormat)
+	message := rule.ErrorMessage
+	if sensitive {
+		// the value of a sensitive field is never written, like ValidStruct does
+		params["value"] = validator.Redacted
+		if message == "" {
+			message = validator.SensitiveMessage
+		}
+	}
+
+	message = validator.RenderMessage(message, params)
+	return strconv.Quote(message), strings.Contains(message, "{value}")
+}
+
+// fieldKey returns the json name of a field, or its name when it has no json name
+func fieldKey(name string, tag reflect.StructTag) string {
+	if jsonName := strings.TrimSpace(strings.Split(tag.Get("json"), ",")[0]); jsonName != "" {
+		return jsonName
+	}
+	return name
+}
+
+// pathExpr returns the expression of a field path, path being relative to the prefix argument
+func pathExpr(path string) string {
+	if path == "" {
+		return "prefix"
+	}
+	return "prefix + " + strconv.Quote(path)
+}
+
+// validateReceiver returns the receiver type name of fn when it is a method Validate() error
+func validateReceiver(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) != 1 || fn.Name.Name != "Validate" {
+		return ""
+	}
+	if fn.Type.Params.NumFields() != 0 || fn.Type.Results.NumFields() != 1 {
+		return ""
+	}
+	if result, ok := fn.Type.Results.List[0].Type.(*ast.Ident); !ok || result.Name != "error" {
+		return ""
+	}
+	return embeddedName(fn.Recv.List[0].Type)
+}
+
+func fieldTag(field *ast.Field) reflect.StructTag {
+	if field.Tag == nil {
+		return ""
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return ""
+	}
+	return reflect.StructTag(tag)
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return ""
+}
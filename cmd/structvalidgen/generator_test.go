@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+func TestGenerator(t *testing.T) {
+	dir := filepath.Join("internal", "sample")
+
+	t.Log("\nTesting the generated sample file is up to date")
+	{
+		g, err := NewGenerator(dir, "validate_gen.go")
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+		src, err := g.Generate(nil)
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+		committed, err := ioutil.ReadFile(filepath.Join(dir, "validate_gen.go"))
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+		if bytes.Equal(src, committed) {
+			t.Logf("%s expected validate_gen.go to match the generator output", success)
+		} else {
+			t.Errorf("%s expected validate_gen.go to match the generator output, run go generate in %s", failed, dir)
+		}
+		if !bytes.Contains(src, []byte("func (v Plain)")) {
+			t.Logf("%s expected no method for type without valid tag", success)
+		} else {
+			t.Errorf("%s expected no method for type without valid tag", failed)
+		}
+	}
+
+	t.Log("\nTesting selected type pulls its nested types")
+	{
+		g, _ := NewGenerator(dir, "validate_gen.go")
+		src, err := g.Generate([]string{"Customer"})
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+		for _, method := range []string{"func (v Customer) Validate", "func (v Address) Validate", "func (v Audit) Validate"} {
+			if bytes.Contains(src, []byte(method)) {
+				t.Logf("%s expected %s", success, method)
+			} else {
+				t.Errorf("%s expected %s", failed, method)
+			}
+		}
+		if !bytes.Contains(src, []byte("func (v Application)")) {
+			t.Logf("%s expected Application to be left out", success)
+		} else {
+			t.Errorf("%s expected Application to be left out", failed)
+		}
+	}
+
+	t.Log("\nTesting types declaring their own Validate method")
+	{
+		g, _ := NewGenerator(dir, "validate_gen.go")
+		src, _ := g.Generate(nil)
+		if bytes.Contains(src, []byte("validator.FieldErrors(prefix+\"balance\", v.Balance.Validate())")) && !bytes.Contains(src, []byte("func (v Money)")) {
+			t.Logf("%s expected Money.Validate to be called and not generated", success)
+		} else {
+			t.Errorf("%s expected Money.Validate to be called and not generated", failed)
+		}
+		if _, err := g.Generate([]string{"Stamp"}); err != nil && strings.Contains(err.Error(), "own Validate") {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected error of type Stamp declaring Validate got %v", failed, err)
+		}
+		if bytes.Contains(src, []byte("v.Contact.validationErrors(prefix+\"contact.\")")) && !bytes.Contains(src, []byte("func (v Contact) Validate")) {
+			t.Logf("%s expected Contact with valid tags to be walked keeping its Validate", success)
+		} else {
+			t.Errorf("%s expected Contact with valid tags to be walked keeping its Validate", failed)
+		}
+	}
+
+	t.Log("\nTesting unknown type")
+	{
+		g, _ := NewGenerator(dir, "validate_gen.go")
+		if _, err := g.Generate([]string{"Missing"}); err != nil && strings.Contains(err.Error(), "Missing") {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected error of type Missing got %v", failed, err)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// Package sample holds the types used to check generated validators against ValidStruct.Valid
+package sample
+
+import (
+	"errors"
+	"time"
+
+	"github.com/zibilal/structiterator/validator"
+)
+
+//go:generate go run github.com/zibilal/structiterator/cmd/structvalidgen -output validate_gen.go
+
+type Address struct {
+	Street string `json:"street" valid:"funcVal:Required"`
+	City   string `json:"city" valid:"funcVal:Required,errorMessage:{field} is needed"`
+	Zip    string `json:"zip" valid:"funcVal:Match,format:^[0-9]{5}$"`
+}
+
+type Audit struct {
+	CreatedBy string `json:"created_by" valid:"funcVal:Required"`
+	note      string
+}
+
+type Customer struct {
+	Audit
+	Name      string      `json:"name" valid:"funcVal:Required;funcVal:MinLength,values:3;funcVal:MaxLength,values:20"`
+	Email     string      `json:"email" valid:"funcVal:Required;funcVal:Email"`
+	Phone     string      `json:"phone" valid:"funcVal:Phone"`
+	Website   string      `json:"website" valid:"funcVal:Url,errorMessage:{value} is not a valid {field}"`
+	Tier      string      `json:"tier" valid:"funcVal:AcceptedValues,values:gold|silver|bronze"`
+	Balance   Money       `json:"balance"`
+	Deposit   *Money      `json:"deposit" valid:"funcVal:Required"`
+	Voucher   string      `json:"voucher" valid:"sensitive;funcVal:AcceptedValues,values:ALPHA|BETA;funcVal:MaxLength,values:5,errorMessage:{value} is too long"`
+	Age       int         `json:"age" valid:"funcVal:AcceptedValues,values:17<->99,errorMessage:{field} must be between {min} and {max}"`
+	Score     float64     `json:"score" valid:"funcVal:Min,values:0;funcVal:Max,values:100"`
+	Tags      []string    `json:"tags" valid:"funcVal:MaxLength,values:2"`
+	Born      string      `json:"born" valid:"funcVal:Date"`
+	Joined    string      `json:"joined" valid:"funcVal:Date,format:yyyy-mm-dd,dateLayout:2006-01-02,errorMessage:{field} must look like {format}"`
+	Address   Address     `json:"address"`
+	Billing   *Address    `json:"billing" valid:"funcVal:Required"`
+	Extra     interface{} `json:"extra" valid:"funcVal:Type,format:object"`
+	UpdatedAt time.Time   `json:"updated_at" valid:"funcVal:Required"`
+	Meta      struct {
+		Source string `json:"source" valid:"funcVal:AcceptedValues,values:web|app"`
+	} `json:"meta"`
+	secret string
+}
+
+type Application struct {
+	Stamp
+	Id             uint    `json:"id" valid:"funcVal:Required"`
+	AppliedTime    string  `json:"applied_time" valid:"funcVal:Required"`
+	ApprovedTime   string  `json:"approved_time" valid:"funcVal:AfterDate,compareKey:applied_time"`
+	Status         string  `json:"status"`
+	ApprovalReason string  `json:"approval_reason" valid:"funcVal:CondRequired,compareKey:status,compareValue:approved|rejected"`
+	Urgent         *bool   `json:"urgent" valid:"funcVal:Required"`
+	Priority       *int    `json:"priority" valid:"omitempty;funcVal:Min,values:1,errorMessage:priority {value} is below 1"`
+	Quota          *int    `json:"quota" valid:"funcVal:NonZero"`
+	Note           string  `json:"note" valid:"omitempty;funcVal:MinLength,values:10"`
+	Contact        Contact `json:"contact"`
+}
+
+// Money validates itself, the generated code calls its Validate method like ValidStruct.Valid does
+type Money int64
+
+func (m Money) Validate() error {
+	if m < 0 {
+		return errors.New("money can not be negative")
+	}
+	return nil
+}
+
+// Stamp validates itself with field errors, those of the embedded Stamp belong to the outer struct
+type Stamp struct {
+	Reviewer string `json:"reviewer"`
+}
+
+func (s *Stamp) Validate() error {
+	if s.Reviewer == "" {
+		return validator.Errors{&validator.FieldError{Field: "reviewer", Rule: "Validate", Err: errors.New("reviewer is missing")}}
+	}
+	return nil
+}
+
+// Contact declares Validate but has valid tags, so it is walked like other nested structs and Validate is not called
+type Contact struct {
+	Email string `json:"email" valid:"funcVal:Email"`
+}
+
+func (c Contact) Validate() error {
+	return errors.New("contact validates itself")
+}
+
+// Plain has no valid tag, no method is generated for it
+type Plain struct {
+	Name string
+}
@@ -0,0 +1,186 @@
+// Code generated by structvalidgen; DO NOT EDIT.
+
+package sample
+
+import (
+	"strings"
+
+	"github.com/zibilal/structiterator/validator"
+)
+
+// Validate checks Address against its valid tags, it reports the same errors as validator.ValidStruct.Valid
+func (v Address) Validate() error {
+	return validator.ErrorsOrNil(v.validationErrors(""))
+}
+
+func (v Address) validationErrors(prefix string) []error {
+	var errs []error
+	var validation validator.Validation
+
+	if err := validation.Required(v.Street, "street", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "street", Rule: "Required", Err: err})
+	}
+	if err := validation.Required(v.City, "city", "city is needed"); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "city", Rule: "Required", Err: err})
+	}
+	if err := validation.Match(v.Zip, "zip", "^[0-9]{5}$", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "zip", Rule: "Match", Err: err})
+	}
+
+	return errs
+}
+
+// Validate checks Application against its valid tags, it reports the same errors as validator.ValidStruct.Valid
+func (v Application) Validate() error {
+	return validator.ErrorsOrNil(v.validationErrors(""))
+}
+
+func (v Application) validationErrors(prefix string) []error {
+	var errs []error
+	var validation validator.Validation
+
+	errs = append(errs, validator.FieldErrors(strings.TrimSuffix(prefix, "."), v.Stamp.Validate())...)
+	if err := validation.Required(v.Id, "id", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "id", Rule: "Required", Err: err})
+	}
+	if err := validation.Required(v.AppliedTime, "applied_time", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "applied_time", Rule: "Required", Err: err})
+	}
+	if err := validation.AfterDate(v, "approved_time", "applied_time", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "approved_time", Rule: "AfterDate", Err: err})
+	}
+	if err := validation.CondRequired(v, "approval_reason", v.ApprovalReason, "status", "approved|rejected", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "approval_reason", Rule: "CondRequired", Err: err})
+	}
+	if err := validation.Required(v.Urgent, "urgent", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "urgent", Rule: "Required", Err: err})
+	}
+	if !validator.IsUnset(v.Priority) {
+		if err := validation.Min(v.Priority, "priority", "1", "priority {value} is below 1"); err != nil {
+			errs = append(errs, &validator.FieldError{Field: prefix + "priority", Rule: "Min", Err: validator.RenderValue(err, v.Priority)})
+		}
+	}
+	if err := validation.NonZero(v.Quota, "quota", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "quota", Rule: "NonZero", Err: err})
+	}
+	if !validator.IsUnset(v.Note) {
+		if err := validation.MinLength(v.Note, "note", "10", ""); err != nil {
+			errs = append(errs, &validator.FieldError{Field: prefix + "note", Rule: "MinLength", Err: err})
+		}
+	}
+	errs = append(errs, v.Contact.validationErrors(prefix+"contact.")...)
+
+	return errs
+}
+
+// Validate checks Audit against its valid tags, it reports the same errors as validator.ValidStruct.Valid
+func (v Audit) Validate() error {
+	return validator.ErrorsOrNil(v.validationErrors(""))
+}
+
+func (v Audit) validationErrors(prefix string) []error {
+	var errs []error
+	var validation validator.Validation
+
+	if err := validation.Required(v.CreatedBy, "created_by", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "created_by", Rule: "Required", Err: err})
+	}
+
+	return errs
+}
+
+// Validate checks Customer against its valid tags, it reports the same errors as validator.ValidStruct.Valid
+func (v Customer) Validate() error {
+	return validator.ErrorsOrNil(v.validationErrors(""))
+}
+
+func (v Customer) validationErrors(prefix string) []error {
+	var errs []error
+	var validation validator.Validation
+
+	errs = append(errs, v.Audit.validationErrors(prefix)...)
+	if err := validation.Required(v.Name, "name", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "name", Rule: "Required", Err: err})
+	}
+	if err := validation.MinLength(v.Name, "name", "3", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "name", Rule: "MinLength", Err: err})
+	}
+	if err := validation.MaxLength(v.Name, "name", "20", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "name", Rule: "MaxLength", Err: err})
+	}
+	if err := validation.Required(v.Email, "email", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "email", Rule: "Required", Err: err})
+	}
+	if err := validation.Email(v.Email, "email", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "email", Rule: "Email", Err: err})
+	}
+	if err := validation.Phone(v.Phone, "phone", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "phone", Rule: "Phone", Err: err})
+	}
+	if err := validation.Url(v.Website, "website", "{value} is not a valid website"); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "website", Rule: "Url", Err: validator.RenderValue(err, v.Website)})
+	}
+	if err := validation.AcceptedValues(v.Tier, "tier", "gold|silver|bronze", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "tier", Rule: "AcceptedValues", Err: err})
+	}
+	errs = append(errs, validator.FieldErrors(prefix+"balance", v.Balance.Validate())...)
+	if err := validation.Required(v.Deposit, "deposit", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "deposit", Rule: "Required", Err: err})
+	}
+	if v.Deposit != nil {
+		errs = append(errs, validator.FieldErrors(prefix+"deposit", v.Deposit.Validate())...)
+	}
+	if err := validation.AcceptedValues(v.Voucher, "voucher", "ALPHA|BETA", "voucher is invalid"); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "voucher", Rule: "AcceptedValues", Err: err})
+	}
+	if err := validation.MaxLength(v.Voucher, "voucher", "5", "[REDACTED] is too long"); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "voucher", Rule: "MaxLength", Err: err})
+	}
+	if err := validation.AcceptedValues(v.Age, "age", "17<->99", "age must be between 17 and 99"); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "age", Rule: "AcceptedValues", Err: err})
+	}
+	if err := validation.Min(v.Score, "score", "0", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "score", Rule: "Min", Err: err})
+	}
+	if err := validation.Max(v.Score, "score", "100", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "score", Rule: "Max", Err: err})
+	}
+	if err := validation.MaxLength(v.Tags, "tags", "2", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "tags", Rule: "MaxLength", Err: err})
+	}
+	if err := validation.Date(v.Born, "born", validator.DateFormat, validator.DateLayout, ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "born", Rule: "Date", Err: err})
+	}
+	if err := validation.Date(v.Joined, "joined", "yyyy-mm-dd", "2006-01-02", "joined must look like yyyy-mm-dd"); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "joined", Rule: "Date", Err: err})
+	}
+	errs = append(errs, v.Address.validationErrors(prefix+"address.")...)
+	if err := validation.Required(v.Billing, "billing", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "billing", Rule: "Required", Err: err})
+	}
+	if v.Billing != nil {
+		errs = append(errs, v.Billing.validationErrors(prefix+"billing.")...)
+	}
+	if err := validation.Type(v.Extra, "extra", "object", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "extra", Rule: "Type", Err: err})
+	}
+	if err := validation.Required(v.UpdatedAt, "updated_at", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "updated_at", Rule: "Required", Err: err})
+	}
+	if err := validation.AcceptedValues(v.Meta.Source, "source", "web|app", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "meta.source", Rule: "AcceptedValues", Err: err})
+	}
+
+	return errs
+}
+
+func (v Contact) validationErrors(prefix string) []error {
+	var errs []error
+	var validation validator.Validation
+
+	if err := validation.Email(v.Email, "email", ""); err != nil {
+		errs = append(errs, &validator.FieldError{Field: prefix + "email", Rule: "Email", Err: err})
+	}
+
+	return errs
+}
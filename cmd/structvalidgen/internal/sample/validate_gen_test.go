@@ -0,0 +1,192 @@
+package sample
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zibilal/structiterator/validator"
+)
+
+const (
+	success = "\u2713"
+	failed  = "\u2717"
+)
+
+// describe formats the field, rule and message of err
+func describe(err error) string {
+	if fieldErr, ok := err.(*validator.FieldError); ok {
+		return fmt.Sprintf("%s %s: %s", fieldErr.Field, fieldErr.Rule, fieldErr.Error())
+	}
+	return err.Error()
+}
+
+// checkAgreement asserts the generated Validate reports the same errors, in the same order, as ValidStruct.Valid.
+// Valid walks the nested structs holding valid tags instead of calling their generated Validate, so their
+// generated checks are compared with the reflective ones too.
+func checkAgreement(t *testing.T, name string, input interface {
+	Validate() error
+}) {
+	checkErrorsAgree(t, name, input, input.Validate())
+}
+
+// checkErrorsAgree asserts err, returned by generated code for input, holds the errors of ValidStruct.Valid in order
+func checkErrorsAgree(t *testing.T, name string, input interface{}, err error) {
+	validtr := validator.NewValidStruct(validator.NewValidationMapper())
+
+	var expected []string
+	for _, err := range validtr.Valid(input) {
+		expected = append(expected, describe(err))
+	}
+
+	var got []string
+	if err != nil {
+		errs, ok := err.(validator.Errors)
+		if !ok {
+			t.Fatalf("%s %s: expected validator.Errors got %T", failed, name, err)
+		}
+		for _, e := range errs {
+			got = append(got, describe(e))
+		}
+	}
+
+	if len(expected) == 0 && got != nil {
+		t.Errorf("%s %s: expected no error got %v", failed, name, got)
+		return
+	}
+	if len(got) != len(expected) {
+		t.Errorf("%s %s: expected %q got %q", failed, name, expected, got)
+		return
+	}
+	for i := range expected {
+		if got[i] != expected[i] {
+			t.Errorf("%s %s: expected %q got %q", failed, name, expected, got)
+			return
+		}
+	}
+	t.Logf("%s %s: %d errors agree", success, name, len(expected))
+}
+
+func validCustomer() Customer {
+	deposit := Money(500)
+	c := Customer{
+		Name:      "Jane Doe",
+		Email:     "jane@example.com",
+		Phone:     "081234567890",
+		Website:   "https://example.com",
+		Tier:      "gold",
+		Balance:   1200,
+		Deposit:   &deposit,
+		Age:       30,
+		Score:     88.5,
+		Tags:      []string{"vip"},
+		Born:      "01/31/1990",
+		Joined:    "2020-05-01",
+		Address:   Address{Street: "Jl. Sudirman 1", City: "Jakarta", Zip: "10220"},
+		Billing:   &Address{Street: "Jl. Thamrin 2", City: "Jakarta", Zip: "10230"},
+		Extra:     map[string]interface{}{"ref": "campaign"},
+		UpdatedAt: time.Date(2021, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	c.CreatedBy = "admin"
+	c.Meta.Source = "web"
+	return c
+}
+
+func TestGeneratedAgreesWithValid(t *testing.T) {
+	t.Log("\nTesting generated Validate against ValidStruct.Valid on Customer")
+	{
+		checkAgreement(t, "valid customer", validCustomer())
+		checkAgreement(t, "empty customer", Customer{})
+
+		c := validCustomer()
+		c.Name = "Al"
+		c.Email = "jane"
+		c.Phone = "12"
+		c.Website = "not a url"
+		c.Tier = "platinum"
+		c.Age = 12
+		c.Score = 101
+		c.Tags = []string{"a", "b", "c"}
+		c.Born = "1990-01-31"
+		c.Joined = "01/05/2020"
+		c.Extra = "text"
+		c.Meta.Source = "pos"
+		c.Voucher = "SECRET-CODE"
+		checkAgreement(t, "invalid customer", c)
+
+		if err := c.Validate(); err != nil && !strings.Contains(err.Error(), "SECRET-CODE") {
+			t.Logf("%s expected sensitive voucher not in %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected sensitive voucher redacted got %v", failed, err)
+		}
+
+		c = validCustomer()
+		c.Address = Address{Zip: "1"}
+		c.Billing = &Address{City: "Bandung", Zip: "abc"}
+		c.CreatedBy = ""
+		checkAgreement(t, "invalid nested structs", c)
+
+		checkAgreement(t, "invalid address", Address{Zip: "1"})
+		checkAgreement(t, "empty audit", Audit{})
+
+		c = validCustomer()
+		c.Billing = nil
+		checkAgreement(t, "nil pointer struct", c)
+
+		c = validCustomer()
+		negative := Money(-1)
+		c.Balance = -100
+		c.Deposit = &negative
+		checkAgreement(t, "types validating themselves", c)
+	}
+
+	t.Log("\nTesting generated Validate against ValidStruct.Valid on Application")
+	{
+		urgent, quota := false, 3
+		checkAgreement(t, "empty application", Application{})
+		checkAgreement(t, "approved application", Application{Stamp: Stamp{Reviewer: "ops"}, Id: 1, AppliedTime: "01/01/2020", ApprovedTime: "01/05/2020", Status: "approved", ApprovalReason: "complete", Urgent: &urgent, Quota: &quota})
+
+		zero := 0
+		checkAgreement(t, "pointers to zero", Application{Stamp: Stamp{Reviewer: "ops"}, Id: 1, AppliedTime: "01/01/2020", Urgent: &urgent, Priority: &zero, Quota: &zero, Note: "short"})
+		checkAgreement(t, "approval before applied", Application{Id: 1, AppliedTime: "01/05/2020", ApprovedTime: "01/01/2020"})
+		checkAgreement(t, "missing approval reason", Application{Id: 1, AppliedTime: "01/01/2020", Status: "rejected"})
+		checkAgreement(t, "invalid contact", Application{Stamp: Stamp{Reviewer: "ops"}, Id: 1, AppliedTime: "01/01/2020", Urgent: &urgent, Quota: &quota, Contact: Contact{Email: "jane"}})
+		checkErrorsAgree(t, "contact walked instead of its Validate", Contact{Email: "jane"}, validator.ErrorsOrNil(Contact{Email: "jane"}.validationErrors("")))
+	}
+
+	t.Log("\nTesting nested generated types are walked with the locale of Valid")
+	{
+		validtr := validator.NewValidStruct(validator.NewValidationMapper())
+		c := validCustomer()
+		c.Address = Address{Zip: "10220"}
+		c.CreatedBy = ""
+		var messages []string
+		for _, err := range validtr.ValidLocale(c, "id") {
+			messages = append(messages, err.Error())
+		}
+		expected := []string{"created_by wajib diisi", "street wajib diisi", "city is needed"}
+		if strings.Join(messages, "; ") == strings.Join(expected, "; ") {
+			t.Logf("%s expected catalog messages %q", success, messages)
+		} else {
+			t.Errorf("%s expected catalog messages %q got %q", failed, expected, messages)
+		}
+	}
+}
+
+func BenchmarkValid(b *testing.B) {
+	validtr := validator.NewValidStruct(validator.NewValidationMapper())
+	c := validCustomer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		validtr.Valid(c)
+	}
+}
+
+func BenchmarkGenerated(b *testing.B) {
+	c := validCustomer()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		c.Validate()
+	}
+}
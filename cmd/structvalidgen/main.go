@@ -0,0 +1,59 @@
+// Command structvalidgen generates Validate methods from the valid tags of the struct types of a package.
+// The generated methods call the funcVal of validator.Validation directly, without reflection,
+// and report the same errors and messages as validator.ValidStruct.Valid with its default settings.
+//
+// Add a go:generate directive in the package holding the types:
+//
+//	//go:generate go run github.com/zibilal/structiterator/cmd/structvalidgen -output validate_gen.go
+//
+// The generated code only knows the valid tags, so ErrorMessageMap, message catalogs, rules added with
+// RegisterRules or loaded from configuration, funcVal registered with RegisterValidator, mod and default tags
+// are not applied. Struct types of other packages, except time.Time, are not walked.
+//
+// A field whose type is declared in the package with its own Validate() error method has that method called,
+// like ValidStruct.Valid does, and no method is generated for the type. A struct type declaring Validate with
+// valid tag on its fields is walked instead, its own method is kept and only its field checks are generated. Type functions registered with
+// RegisterTypeFunc and the driver.Valuer of other packages, like sql.NullString, are only known at runtime.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	dir := flag.String("dir", ".", "directory of the package holding the types")
+	output := flag.String("output", "validate_gen.go", "name of the generated file, written in dir")
+	types := flag.String("type", "", "comma separated type names, all struct types holding valid tag when empty")
+	flag.Parse()
+
+	if err := run(*dir, *output, *types); err != nil {
+		fmt.Fprintln(os.Stderr, "structvalidgen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(dir, output, types string) error {
+	g, err := NewGenerator(dir, output)
+	if err != nil {
+		return err
+	}
+
+	var names []string
+	for _, name := range strings.Split(types, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	src, err := g.Generate(names)
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(filepath.Join(dir, output), src, 0644)
+}
@@ -0,0 +1,17 @@
+// Command tagvet runs the tagcheck analyzer, checking valid and query struct tags.
+// Run it alone or through go vet:
+//
+//	go install github.com/zibilal/structiterator/cmd/tagvet
+//	go vet -vettool=$(which tagvet) ./...
+//
+// Pass the funcVal added with RegisterValidator with -funcs, like -funcs=Nik,PostalCode.
+package main
+
+import (
+	"github.com/zibilal/structiterator/tagcheck"
+	"golang.org/x/tools/go/analysis/singlechecker"
+)
+
+func main() {
+	singlechecker.Main(tagcheck.Analyzer)
+}
@@ -0,0 +1,444 @@
+// Package tagcheck defines an analyzer checking the valid tags of package validator
+// and the query tags of package querycomposer, reporting mistakes that are otherwise
+// silently ignored or only found at runtime.
+package tagcheck
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/zibilal/structiterator/validator"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+)
+
+const doc = `check valid and query struct tags
+
+The valid tag is checked for unknown or duplicated keys, missing or unknown funcVal,
+invalid regular expressions, date layouts and values, comma ending errorMessage early,
+and compareKey naming a field missing in the struct. The query tag is checked for missing
+column names, unknown options, duplicated columns and fields skipped by querycomposer.`
+
+// Analyzer checks valid and query struct tags
+var Analyzer = &analysis.Analyzer{
+	Name:     "tagcheck",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// funcs holds the funcVal added with RegisterValidator, given by flag
+var funcs string
+
+func init() {
+	Analyzer.Flags.StringVar(&funcs, "funcs", "", "comma separated funcVal registered with RegisterValidator")
+}
+
+// ruleKeys are the keys of a rule in valid tag
+var ruleKeys = []string{"funcVal", "errorMessage", "format", "compareKey", "compareValue", "dateLayout", "values", "expr"}
+
+func run(pass *analysis.Pass) (interface{}, error) {
+	funcVals := defaultFuncVals()
+	for _, name := range strings.Split(funcs, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			funcVals[name] = true
+		}
+	}
+
+	inspect := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	inspect.Preorder([]ast.Node{(*ast.StructType)(nil)}, func(n ast.Node) {
+		st := n.(*ast.StructType)
+		checkValidTags(pass, st, funcVals)
+		checkQueryTags(pass, st)
+	})
+
+	return nil, nil
+}
+
+func defaultFuncVals() map[string]bool {
+	funcVals := make(map[string]bool)
+	t := reflect.TypeOf(validator.Validation{})
+	for i := 0; i < t.NumMethod(); i++ {
+		funcVals[t.Method(i).Name] = true
+	}
+	for _, name := range validator.StructRules() {
+		funcVals[name] = true
+	}
+	return funcVals
+}
+
+// structField is a field of a struct type with its tag
+type structField struct {
+	field *ast.Field
+	name  string
+	tag   reflect.StructTag
+}
+
+func structFields(st *ast.StructType) []structField {
+	var fields []structField
+	for _, field := range st.Fields.List {
+		tag := fieldTag(field)
+		if len(field.Names) == 0 {
+			fields = append(fields, structField{field: field, name: embeddedName(field.Type), tag: tag})
+			continue
+		}
+		for _, name := range field.Names {
+			fields = append(fields, structField{field: field, name: name.Name, tag: tag})
+		}
+	}
+	return fields
+}
+
+func checkValidTags(pass *analysis.Pass, st *ast.StructType, funcVals map[string]bool) {
+	fields := structFields(st)
+
+	// compareKey is matched with the field name or the json name, like Validation.CondRequired does
+	keys := make(map[string]bool)
+	for _, f := range fields {
+		keys[f.name] = true
+		if jsonName := strings.Split(f.tag.Get("json"), ",")[0]; jsonName != "" {
+			keys[jsonName] = true
+		}
+	}
+
+	for _, f := range fields {
+		value, offset, found := lookupTag(f.tag, "valid")
+		if !found || value == "" {
+			continue
+		}
+		pos := func(idx int) token.Pos {
+			return tagPos(f.field, offset, idx)
+		}
+
+		start := 0
+		for _, ruleText := range validator.SplitTag(value, ';') {
+			checkRule(pass, ruleText, start, pos, keys, funcVals)
+			start += len(ruleText) + 1
+		}
+	}
+}
+
+// checkRule checks one rule of a valid tag, start is the offset of the rule in the tag value
+func checkRule(pass *analysis.Pass, ruleText string, start int, pos func(int) token.Pos, keys, funcVals map[string]bool) {
+	if strings.TrimSpace(ruleText) == "" {
+		pass.Reportf(pos(start), "empty rule in valid tag")
+		return
+	}
+	if option := strings.TrimSpace(ruleText); option == validator.SensitiveOption || option == validator.OmitEmptyOption {
+		return
+	}
+
+	attrs := make(map[string]string)
+	lastKey := ""
+	partStart := start
+	for _, part := range validator.SplitTag(ruleText, ',') {
+		partPos := pos(partStart)
+		partStart += len(part) + 1
+
+		kv := strings.SplitN(part, ":", 2)
+		if len(kv) < 2 {
+			if lastKey == "errorMessage" {
+				pass.Reportf(partPos, "comma ends errorMessage, %q is ignored", part)
+			} else {
+				pass.Reportf(partPos, "%q in valid tag has no key", part)
+			}
+			continue
+		}
+
+		key := kv[0]
+		if !contains(ruleKeys, key) {
+			if suggestion := suggest(key, ruleKeys); suggestion != "" {
+				pass.Reportf(partPos, "unknown key %s in valid tag, did you mean %s?", key, suggestion)
+			} else {
+				pass.Reportf(partPos, "unknown key %s in valid tag", key)
+			}
+			continue
+		}
+		if _, duplicated := attrs[key]; duplicated {
+			pass.Reportf(partPos, "duplicated key %s in rule, only the last one is used", key)
+		}
+		attrs[key] = kv[1]
+		lastKey = key
+	}
+
+	rulePos := pos(start)
+	if text, found := attrs["expr"]; found {
+		checkExpr(pass, rulePos, text, attrs, keys)
+		return
+	}
+
+	funcVal := attrs["funcVal"]
+	if funcVal == "" {
+		if _, found := attrs["funcVal"]; !found {
+			pass.Reportf(rulePos, "rule has no funcVal")
+		} else {
+			pass.Reportf(rulePos, "empty funcVal")
+		}
+		return
+	}
+	used := []string{funcVal}
+	if validator.IsRuleExpr(funcVal) {
+		expr, err := validator.ParseRuleExpr(funcVal)
+		if err != nil {
+			pass.Reportf(rulePos, "%s", err.Error())
+			return
+		}
+		used = used[:0]
+		for _, operand := range expr.FuncVals() {
+			used = append(used, operand.FuncVal)
+		}
+	}
+	for _, name := range used {
+		if !funcVals[name] {
+			reportUnknownFuncVal(pass, rulePos, name, funcVals)
+			return
+		}
+	}
+
+	rule := validator.ParseRules(ruleText)[0]
+	if err := validator.CheckRule(rule); err != nil {
+		pass.Reportf(rulePos, "%s", err.Error())
+	}
+
+	if rule.CompareKey != "" && !keys[rule.CompareKey] {
+		pass.Reportf(rulePos, "compareKey %s is not a field of the struct", rule.CompareKey)
+	}
+}
+
+// checkExpr checks the expression of an expr rule, the first part of each field path must be a field of the struct
+func checkExpr(pass *analysis.Pass, pos token.Pos, text string, attrs map[string]string, keys map[string]bool) {
+	if _, found := attrs["funcVal"]; found {
+		pass.Reportf(pos, "rule has both funcVal and expr, funcVal is ignored")
+	}
+
+	expr, err := validator.ParseExpr(text)
+	if err != nil {
+		pass.Reportf(pos, "%s", err.Error())
+		return
+	}
+	for _, field := range expr.Fields() {
+		if name := strings.Split(field, ".")[0]; !keys[name] {
+			pass.Reportf(pos, "expr reads %s, not a field of the struct", field)
+		}
+	}
+}
+
+// reportUnknownFuncVal reports funcVal missing in funcVals, suggesting the closest known funcVal
+func reportUnknownFuncVal(pass *analysis.Pass, pos token.Pos, funcVal string, funcVals map[string]bool) {
+	names := make([]string, 0, len(funcVals))
+	for name := range funcVals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if suggestion := suggest(funcVal, names); suggestion != "" {
+		pass.Reportf(pos, "unknown funcVal %s, did you mean %s?", funcVal, suggestion)
+	} else {
+		pass.Reportf(pos, "unknown funcVal %s, register it with -funcs when it is added with RegisterValidator", funcVal)
+	}
+}
+
+func checkQueryTags(pass *analysis.Pass, st *ast.StructType) {
+	fields := structFields(st)
+
+	tagged := false
+	for _, f := range fields {
+		if _, found := f.tag.Lookup("query"); found {
+			tagged = true
+			break
+		}
+	}
+	if !tagged {
+		return
+	}
+
+	columns := make(map[string]string)
+	for _, f := range fields {
+		value, found := f.tag.Lookup("query")
+		skipped := skippedByQuery(pass.TypesInfo.TypeOf(f.field.Type))
+
+		if !found {
+			if !skipped {
+				pass.Reportf(f.field.Pos(), "field %s has no query tag, querycomposer adds an empty column for it", f.name)
+			}
+			continue
+		}
+
+		if skipped {
+			pass.Reportf(f.field.Tag.Pos(), "querycomposer skips fields of struct, slice and map type, query tag of %s is ignored", f.name)
+			continue
+		}
+
+		parts := strings.Split(value, ",")
+		column := strings.TrimSpace(parts[0])
+		if column == "" {
+			pass.Reportf(f.field.Tag.Pos(), "query tag of %s has no column name", f.name)
+		} else if other, duplicated := columns[column]; duplicated {
+			pass.Reportf(f.field.Tag.Pos(), "duplicated query column %s, also used by %s", column, other)
+		} else {
+			columns[column] = f.name
+		}
+
+		for _, option := range parts[1:] {
+			if option != "primary" {
+				pass.Reportf(f.field.Tag.Pos(), "unknown query option %q, expected primary", option)
+			}
+		}
+	}
+}
+
+// skippedByQuery reports whether querycomposer leaves out a field of type t
+func skippedByQuery(t types.Type) bool {
+	if t == nil {
+		return false
+	}
+	switch t.Underlying().(type) {
+	case *types.Struct, *types.Slice, *types.Map:
+		return true
+	}
+	return false
+}
+
+func fieldTag(field *ast.Field) reflect.StructTag {
+	if field.Tag == nil {
+		return ""
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return ""
+	}
+	return reflect.StructTag(tag)
+}
+
+func embeddedName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.StarExpr:
+		return embeddedName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	}
+	return ""
+}
+
+// lookupTag is reflect.StructTag.Lookup also returning the offset of the value in tag
+func lookupTag(tag reflect.StructTag, key string) (string, int, bool) {
+	offset := 0
+	for tag != "" {
+		i := 0
+		for i < len(tag) && tag[i] == ' ' {
+			i++
+		}
+		tag = tag[i:]
+		offset += i
+		if tag == "" {
+			break
+		}
+
+		i = 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			break
+		}
+		name := string(tag[:i])
+		tag = tag[i+1:]
+		offset += i + 1
+
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			break
+		}
+		qvalue := string(tag[:i+1])
+		tag = tag[i+1:]
+
+		if name == key {
+			value, err := strconv.Unquote(qvalue)
+			if err != nil {
+				break
+			}
+			if value != qvalue[1:len(qvalue)-1] {
+				return value, -1, true // escaped value, offsets inside it are not known
+			}
+			return value, offset + 1, true
+		}
+		offset += i + 1
+	}
+	return "", 0, false
+}
+
+// tagPos returns the position of idx in the tag value starting at offset, or the position of the tag
+// when the tag is not a raw string literal
+func tagPos(field *ast.Field, offset, idx int) token.Pos {
+	if offset < 0 || !strings.HasPrefix(field.Tag.Value, "`") {
+		return field.Tag.Pos()
+	}
+	return field.Tag.Pos() + token.Pos(1+offset+idx)
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// suggest returns the candidate closest to name, when it differs by case or by at most two edits
+func suggest(name string, candidates []string) string {
+	best, bestDistance := "", 3
+	for _, candidate := range candidates {
+		if strings.EqualFold(name, candidate) {
+			return candidate
+		}
+		if d := distance(name, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+// distance is the Levenshtein distance of a and b
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
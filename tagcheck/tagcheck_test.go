@@ -0,0 +1,16 @@
+package tagcheck
+
+import (
+	"testing"
+
+	"golang.org/x/tools/go/analysis/analysistest"
+)
+
+func TestAnalyzer(t *testing.T) {
+	if err := Analyzer.Flags.Set("funcs", "Nik"); err != nil {
+		t.Fatal(err)
+	}
+	defer Analyzer.Flags.Set("funcs", "")
+
+	analysistest.Run(t, analysistest.TestData(), Analyzer, "a")
+}
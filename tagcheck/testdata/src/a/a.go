@@ -0,0 +1,44 @@
+package a
+
+import "time"
+
+type User struct {
+	Name     string  `json:"name" valid:"funcVal:Required,errorMessage:{field} is required"`
+	Email    string  `json:"email" valid:"funcval:Required"`                                             // want `unknown key funcval in valid tag, did you mean funcVal\?` `rule has no funcVal`
+	Phone    string  `json:"phone" valid:"funcVal:Require"`                                              // want `unknown funcVal Require, did you mean Required\?`
+	Code     string  `json:"code" valid:"funcVal:Match,format:^([0-9]$"`                                 // want `invalid regular expression`
+	Born     string  `json:"born" valid:"funcVal:Date,format:yyyy-mm-dd,dateLayout:YYYY"`                // want `invalid date layout YYYY`
+	Reason   string  `json:"reason" valid:"funcVal:CondRequired,compareKy:status,compareValue:approved"` // want `unknown key compareKy in valid tag, did you mean compareKey\?` `CondRequired needs compareKey and compareValue`
+	Approved string  `json:"approved" valid:"funcVal:AfterDate,compareKey:applied"`                      // want `compareKey applied is not a field of the struct`
+	Status   string  `json:"status" valid:"funcVal:AcceptedValues,values:new|done;"`                     // want `empty rule in valid tag`
+	Note     string  `valid:"funcVal:Required,errorMessage:Note is needed, please fill it"`              // want `comma ends errorMessage, " please fill it" is ignored`
+	Level    int     `valid:"funcVal:Min,values:1,values:2"`                                             // want `duplicated key values in rule, only the last one is used`
+	Custom   string  `valid:"funcVal:Nik"`
+	Applied  string  `json:"applied_time" valid:"funcVal:Required"`
+	Later    string  `valid:"funcVal:AfterDate,compareKey:applied_time"`
+	Login    string  `valid:"funcVal:Unique,format:users.login"`
+	Secret   string  `valid:"sensitive;funcVal:Password,compareKey:email"`
+	Token    string  `valid:"sensitive,funcVal:Required"`   // want `"sensitive" in valid tag has no key`
+	Agent    string  `valid:"funcVal:Exists,format:agents"` // want `invalid lookup target "agents", expected format table.column`
+	Referrer *int    `valid:"omitempty;funcVal:NonZero"`
+	Contact  string  `valid:"funcVal:Email|Phone"`
+	Handle   string  `valid:"funcVal:!Match:^[0-9]+$&MinLength:3"`
+	Channel  string  `valid:"funcVal:Email|Fax"`                     // want `unknown funcVal Fax, did you mean Max\?`
+	Coupon   string  `valid:"funcVal:(MinLength:3|Match:'^[A-Z]+$'"` // want `invalid rule expression \(MinLength:3\|Match:'\^\[A-Z\]\+\$': parenthesis at 0 is not closed`
+	Discount float64 `json:"discount" valid:"expr:Discount <= Level * 0.5"`
+	Rebate   float64 `valid:"expr:Rebate <= Price"`             // want `expr reads Price, not a field of the struct`
+	Bonus    float64 `valid:"expr:Bonus <= (Level * 2"`         // want `invalid expression Bonus <= \(Level \* 2: parenthesis at 9 is not closed`
+	Owner    string  `valid:"funcVal:Email|Unique:users.email"` // want `funcVal Unique is not supported in a rule expression`
+	Region   string  `valid:"expr:Region != 'a,b;c',errorMessage:{field} isn't served"`
+}
+
+type Order struct {
+	Id      int       `query:"orders.id,primary"`
+	Name    string    `query:"orders.name"`
+	Title   string    `query:"orders.name"`       // want `duplicated query column orders.name, also used by Name`
+	Amount  float64   `query:",primary"`          // want `query tag of Amount has no column name`
+	Status  string    `query:"orders.status,key"` // want `unknown query option "key", expected primary`
+	Created time.Time `query:"orders.created"`    // want `querycomposer skips fields of struct, slice and map type, query tag of Created is ignored`
+	Items   []string
+	Comment string // want `field Comment has no query tag, querycomposer adds an empty column for it`
+}
@@ -0,0 +1,147 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// BindDateLayout is the layout of a date without time, accepted by BindValues besides RFC3339 and DateLayout
+const BindDateLayout = "2006-01-02"
+
+// BindValues fills the fields of dst, a pointer to struct, from values like a query string or a form body.
+// A field is read from the value named by its form tag, json name or field name, a form tag "-" skips the field.
+// Values are converted into the field type: numbers, bools, durations, times as RFC3339, DateLayout or BindDateLayout,
+// and slices from repeated values, comma separated values or both, like ?status=a&status=b or ?ids=1,2,3.
+// A pointer field is allocated. An empty value leaves a field other than a string unchanged, like ValidCSV skips empty cells.
+// A value that can not be converted is returned as a *FieldError with rule Type, in the format of validation errors:
+// its Field is the json name or field name, like the path of a validation error, while the message names the value bound.
+// The field is left unchanged. The value of a sensitive field is redacted.
+func (s *ValidStruct) BindValues(dst interface{}, values url.Values) []error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return []error{errors.New("bind values only accept dst type pointer to struct")}
+	}
+
+	return s.bindFields(v.Elem(), func(ft reflect.StructField) (string, []string) {
+		name := FormName(ft)
+		if name == "-" {
+			return "", nil
+		}
+		return name, values[name]
+	})
+}
+
+// BindPath fills the fields of dst, a pointer to struct, having a path tag from params, the path parameters of a route
+// like /orders/{id}. Values are converted like BindValues.
+func (s *ValidStruct) BindPath(dst interface{}, params map[string]string) []error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return []error{errors.New("bind path only accept dst type pointer to struct")}
+	}
+
+	return s.bindFields(v.Elem(), func(ft reflect.StructField) (string, []string) {
+		name := strings.Split(ft.Tag.Get("path"), ",")[0]
+		if name == "" || name == "-" {
+			return "", nil
+		}
+		if param, found := params[name]; found {
+			return name, []string{param}
+		}
+		return name, nil
+	})
+}
+
+// FormName returns the name a field is bound from: its form tag, json name or field name
+func FormName(ft reflect.StructField) string {
+	if name := strings.Split(ft.Tag.Get("form"), ",")[0]; name != "" {
+		return name
+	}
+	if name := strings.Split(ft.Tag.Get("json"), ",")[0]; name != "" {
+		return name
+	}
+	return ft.Name
+}
+
+// bindFields sets each exported field of v from the texts returned by lookup, a field without text is left unchanged
+func (s *ValidStruct) bindFields(v reflect.Value, lookup func(ft reflect.StructField) (string, []string)) []error {
+	var errs []error
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+		if ft.PkgPath != "" || (isNestedStruct(ft.Type) && !implements(ft.Type, scannerType)) {
+			continue
+		}
+
+		name, texts := lookup(ft)
+		if name == "" || len(texts) == 0 {
+			continue
+		}
+
+		if err := s.bindText(v.Field(i), texts); err != nil {
+			errs = append(errs, &FieldError{
+				Field: fieldKey(ft),
+				Rule:  "Type",
+				Err:   fmt.Errorf("%s has invalid value %s", name, displayValue(strings.Join(texts, ","), s.isSensitiveField(t, ft))),
+			})
+		}
+	}
+
+	return errs
+}
+
+// bindText converts texts into the type of v and sets it, a slice takes every text and other types the first one.
+// An empty text, like ?limit= or an empty form input, is absent for types other than string and leaves v unchanged.
+func (s *ValidStruct) bindText(v reflect.Value, texts []string) error {
+	target := v
+	if v.Kind() == reflect.Ptr {
+		target = reflect.New(v.Type().Elem()).Elem()
+	}
+	if target.Kind() != reflect.String && target.Kind() != reflect.Slice && strings.TrimSpace(texts[0]) == "" {
+		return nil
+	}
+
+	switch {
+	case target.Kind() == reflect.Slice:
+		var splits []string
+		for _, text := range texts {
+			for _, split := range strings.Split(text, ",") {
+				if split = strings.TrimSpace(split); split != "" {
+					splits = append(splits, split)
+				}
+			}
+		}
+		slice := reflect.MakeSlice(target.Type(), len(splits), len(splits))
+		for i, split := range splits {
+			if err := s.bindText(slice.Index(i), []string{split}); err != nil {
+				return err
+			}
+		}
+		target.Set(slice)
+	case target.Type() == timeType:
+		if texts[0] == "now" {
+			return errors.New("now is only accepted by default tag")
+		}
+		tm, err := time.Parse(BindDateLayout, texts[0])
+		if err != nil {
+			if err := s.parseValue(target, texts[0]); err != nil {
+				return err
+			}
+		} else {
+			target.Set(reflect.ValueOf(tm))
+		}
+	default:
+		if err := s.parseValue(target, texts[0]); err != nil {
+			return err
+		}
+	}
+
+	if v.Kind() == reflect.Ptr {
+		v.Set(target.Addr())
+	}
+	return nil
+}
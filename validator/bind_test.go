@@ -0,0 +1,131 @@
+package validator
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type OrderFilter struct {
+	Status   []string   `form:"status"`
+	Ids      []int      `form:"ids"`
+	Paid     bool       `form:"paid"`
+	Limit    *int       `form:"limit" valid:"funcVal:Max,values:100"`
+	From     time.Time  `form:"from"`
+	Until    *time.Time `form:"until"`
+	Timeout  time.Duration
+	Customer int    `path:"customer"`
+	Internal string `form:"-"`
+}
+
+func TestBindValues(t *testing.T) {
+	validtr := NewValidStruct(NewValidationMapper())
+
+	t.Log("\nTesting conversion of query values")
+	{
+		values, _ := url.ParseQuery("status=paid&status=shipped&ids=1,2,3&paid=true&limit=10&from=2019-11-23&until=2019-11-30T10:00:00Z&Timeout=5s&Internal=x")
+
+		var filter OrderFilter
+		errs := validtr.BindValues(&filter, values)
+		until := time.Date(2019, 11, 30, 10, 0, 0, 0, time.UTC)
+		if len(errs) == 0 &&
+			reflect.DeepEqual(filter.Status, []string{"paid", "shipped"}) &&
+			reflect.DeepEqual(filter.Ids, []int{1, 2, 3}) &&
+			filter.Paid && filter.Limit != nil && *filter.Limit == 10 &&
+			filter.From.Equal(time.Date(2019, 11, 23, 0, 0, 0, 0, time.UTC)) &&
+			filter.Until != nil && filter.Until.Equal(until) &&
+			filter.Timeout == 5*time.Second && filter.Internal == "" {
+			t.Logf("%s expected converted values %+v", success, filter)
+		} else {
+			t.Errorf("%s expected converted values got %+v and %v", failed, filter, errs)
+		}
+	}
+
+	t.Log("\nTesting conversion failures have the format of validation errors")
+	{
+		values, _ := url.ParseQuery("ids=1,x&paid=maybe&limit=500&from=now")
+
+		var filter OrderFilter
+		errs := validtr.BindValues(&filter, values)
+		expected := []FieldError{
+			{Field: "Ids", Rule: "Type"},
+			{Field: "Paid", Rule: "Type"},
+			{Field: "From", Rule: "Type"},
+		}
+		matched := len(errs) == len(expected)
+		for i := 0; matched && i < len(errs); i++ {
+			fieldErr, ok := errs[i].(*FieldError)
+			matched = ok && fieldErr.Field == expected[i].Field && fieldErr.Rule == expected[i].Rule
+		}
+		if matched && errs[0].Error() == "ids has invalid value 1,x" && filter.Ids == nil {
+			t.Logf("%s expected errors %v", success, errs)
+		} else {
+			t.Errorf("%s expected errors of ids, paid and from got %v", failed, errs)
+		}
+
+		if errs := validtr.Valid(filter); len(errs) == 1 && errs[0].(*FieldError).Field == "Limit" {
+			t.Logf("%s expected bound limit to be validated", success)
+		} else {
+			t.Errorf("%s expected error of Limit got %v", failed, errs)
+		}
+	}
+
+	t.Log("\nTesting conversion and validation errors share the field path")
+	{
+		type Page struct {
+			Size int `form:"limit" json:"page_size" valid:"funcVal:Max,values:100"`
+		}
+
+		var page Page
+		bindErrs := validtr.BindValues(&page, url.Values{"limit": {"ten"}})
+		page.Size = 500
+		validErrs := validtr.Valid(page)
+		if len(bindErrs) == 1 && len(validErrs) == 1 &&
+			bindErrs[0].(*FieldError).Field == "page_size" && validErrs[0].(*FieldError).Field == "page_size" {
+			t.Logf("%s expected field page_size for both errors", success)
+		} else {
+			t.Errorf("%s expected field page_size for both errors got %v and %v", failed, bindErrs, validErrs)
+		}
+	}
+
+	t.Log("\nTesting empty values are absent")
+	{
+		values, _ := url.ParseQuery("status=&ids=&paid=&limit=&from=&until=&Timeout=")
+
+		filter := OrderFilter{Paid: true}
+		errs := validtr.BindValues(&filter, values)
+		if len(errs) == 0 && filter.Paid && filter.Limit == nil && filter.From.IsZero() && filter.Until == nil && len(filter.Status) == 0 {
+			t.Logf("%s expected fields unchanged %+v", success, filter)
+		} else {
+			t.Errorf("%s expected fields unchanged got %+v and %v", failed, filter, errs)
+		}
+	}
+
+	t.Log("\nTesting path parameters")
+	{
+		var filter OrderFilter
+		errs := validtr.BindPath(&filter, map[string]string{"customer": "42"})
+		if len(errs) == 0 && filter.Customer == 42 {
+			t.Logf("%s expected customer 42", success)
+		} else {
+			t.Errorf("%s expected customer 42 got %d and %v", failed, filter.Customer, errs)
+		}
+
+		errs = validtr.BindPath(&filter, map[string]string{"customer": "jane"})
+		if len(errs) == 1 && errs[0].Error() == "customer has invalid value jane" && errs[0].(*FieldError).Field == "Customer" {
+			t.Logf("%s expected error %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected error of customer got %v", failed, errs)
+		}
+	}
+
+	t.Log("\nTesting dst must be a pointer to struct")
+	{
+		if errs := validtr.BindValues(OrderFilter{}, url.Values{}); len(errs) == 1 {
+			t.Logf("%s expected error %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected error got %v", failed, errs)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package validator
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// CSVError is a failed cell of a CSV file. Column is the header of the cell, empty when the
+// error belongs to a field not read from a column, like a field of a nested struct.
+type CSVError struct {
+	Row    int
+	Column string
+	Err    error
+}
+
+func (e CSVError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("row %d: %s", e.Row, e.Err.Error())
+	}
+	return fmt.Sprintf("row %d, column %s: %s", e.Row, e.Column, e.Err.Error())
+}
+
+// CSVRow is the result of one data row. Row is the line number where the row starts, the header being line 1.
+// Value is a pointer to the struct filled from the row.
+type CSVRow struct {
+	Row    int
+	Record []string
+	Value  interface{}
+	Errors []CSVError
+}
+
+// CSVReport holds the rows of a CSV file with their errors. Unmapped lists the headers matching no field.
+type CSVReport struct {
+	Header   []string
+	Unmapped []string
+	Rows     []CSVRow
+}
+
+// ValidCSV reads a CSV file with a header row into values of the struct type of sample and validates each row like Valid.
+// A header is matched with the field having it as csv tag, json name or field name, ignoring case. Cells are converted
+// into the field type, an empty cell leaves the field empty. Conversion and validation errors are reported by row and column.
+func (s *ValidStruct) ValidCSV(r io.Reader, sample interface{}) (*CSVReport, error) {
+	return s.ValidCSVLocale(r, sample, s.DefaultLocale)
+}
+
+// ValidCSVLocale validates a CSV file like ValidCSV, building error messages from the catalog of locale
+func (s *ValidStruct) ValidCSVLocale(r io.Reader, sample interface{}, locale string) (*CSVReport, error) {
+	return s.ValidCSVLocaleContext(context.Background(), r, sample, locale)
+}
+
+// ValidCSVContext validates a CSV file like ValidCSV, passing ctx to the Lookup of funcVal Unique and Exists
+func (s *ValidStruct) ValidCSVContext(ctx context.Context, r io.Reader, sample interface{}) (*CSVReport, error) {
+	return s.ValidCSVLocaleContext(ctx, r, sample, s.DefaultLocale)
+}
+
+// ValidCSVLocaleContext validates a CSV file like ValidCSVLocale, passing ctx to the Lookup of funcVal Unique and Exists.
+// The rows read before ctx is done are returned with the context error.
+func (s *ValidStruct) ValidCSVLocaleContext(ctx context.Context, r io.Reader, sample interface{}, locale string) (*CSVReport, error) {
+	t, err := sampleStructType(sample)
+	if err != nil {
+		return nil, errors.New("valid csv only accept sample type struct")
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		if err == io.EOF {
+			return nil, errors.New("csv has no header row")
+		}
+		return nil, err
+	}
+
+	report := &CSVReport{Header: header}
+	columns := make([]int, len(header)) // field index of each column, -1 when unmapped
+	fieldColumns := make(map[int]string)
+	for i, name := range header {
+		columns[i] = csvField(t, strings.TrimSpace(name))
+		if columns[i] < 0 {
+			report.Unmapped = append(report.Unmapped, name)
+		} else {
+			fieldColumns[columns[i]] = name
+		}
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return report, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		row := CSVRow{Row: line, Record: record}
+
+		value := reflect.New(t)
+		converted := make(map[int]bool)
+		for i, cell := range record {
+			if i >= len(columns) || columns[i] < 0 || cell == "" {
+				continue
+			}
+			if err := s.parseCell(value.Elem().Field(columns[i]), cell); err != nil {
+				if s.isSensitiveField(t, t.Field(columns[i])) {
+					err = fmt.Errorf("invalid value %s", Redacted) // the conversion error may hold the value too
+				} else {
+					err = fmt.Errorf("invalid value %s: %s", cell, err.Error())
+				}
+				row.Errors = append(row.Errors, CSVError{Row: line, Column: header[i], Err: err})
+				converted[columns[i]] = false
+				continue
+			}
+			converted[columns[i]] = true
+		}
+
+		for _, err := range s.prepare(value.Elem()) {
+			row.Errors = append(row.Errors, CSVError{Row: line, Err: err})
+		}
+
+		walkFields(value.Elem(), "", s.isTypeValidated, func(parent, fv reflect.Value, ft reflect.StructField, path string) error {
+			column := ""
+			if parent.Type() == t && len(ft.Index) == 1 {
+				column = fieldColumns[ft.Index[0]]
+				if ok, found := converted[ft.Index[0]]; found && !ok {
+					return nil // the conversion error is reported already
+				}
+			}
+			for _, err := range s.resolveLookups(ctx, s.validField(locale, parent, fv, ft, path)) {
+				row.Errors = append(row.Errors, CSVError{Row: line, Column: column, Err: err})
+			}
+			return nil
+		})
+
+		row.Value = value.Interface()
+		report.Rows = append(report.Rows, row)
+	}
+
+	return report, nil
+}
+
+// csvField returns the index of the field of t matching header, or -1
+func csvField(t reflect.Type, header string) int {
+	for _, names := range []func(ft reflect.StructField) string{
+		func(ft reflect.StructField) string { return strings.Split(ft.Tag.Get("csv"), ",")[0] },
+		func(ft reflect.StructField) string { return strings.Split(ft.Tag.Get("json"), ",")[0] },
+		func(ft reflect.StructField) string { return ft.Name },
+	} {
+		for i := 0; i < t.NumField(); i++ {
+			ft := t.Field(i)
+			if ft.PkgPath != "" || ft.Tag.Get("csv") == "-" {
+				continue
+			}
+			if name := names(ft); name != "" && strings.EqualFold(name, header) {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+func (s *ValidStruct) parseCell(fv reflect.Value, cell string) error {
+	if fv.Kind() == reflect.Ptr {
+		target := reflect.New(fv.Type().Elem())
+		if err := s.parseValue(target.Elem(), cell); err != nil {
+			return err
+		}
+		fv.Set(target)
+		return nil
+	}
+	return s.parseValue(fv, cell)
+}
+
+// Valid reports whether no row has error
+func (r *CSVReport) Valid() bool {
+	return len(r.Errors()) == 0
+}
+
+// Errors returns the errors of all rows, in row order
+func (r *CSVReport) Errors() []CSVError {
+	var errs []CSVError
+	for _, row := range r.Rows {
+		errs = append(errs, row.Errors...)
+	}
+	return errs
+}
+
+// WriteCSV writes the file back with an errors column appended, holding the messages of each row separated by semicolon
+func (r *CSVReport) WriteCSV(w io.Writer) error {
+	writer := csv.NewWriter(w)
+
+	header := append(append([]string{}, r.Header...), "errors")
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, row := range r.Rows {
+		messages := make([]string, 0, len(row.Errors))
+		for _, err := range row.Errors {
+			if err.Column == "" {
+				messages = append(messages, err.Err.Error())
+			} else {
+				messages = append(messages, err.Column+": "+err.Err.Error())
+			}
+		}
+
+		record := make([]string, len(r.Header), len(r.Header)+1)
+		copy(record, row.Record)
+		if err := writer.Write(append(record, strings.Join(messages, "; "))); err != nil {
+			return err
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
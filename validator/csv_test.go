@@ -0,0 +1,100 @@
+package validator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type AgentRow struct {
+	Code     string   `csv:"agent_code" valid:"funcVal:Required;funcVal:Match,format:^AG[0-9]{3}$"`
+	Name     string   `json:"name" valid:"funcVal:Required" mod:"trim"`
+	Email    string   `valid:"funcVal:Email"`
+	Age      int      `json:"age" valid:"funcVal:Min,values:17"`
+	Active   bool     `json:"active"`
+	Quota    *float64 `json:"quota"`
+	internal string
+}
+
+const agentCSV = `agent_code,name,email,age,active,quota,region
+AG001, Budi ,budi@example.com,30,true,1.5,west
+AG02,,budi,16,false,,east
+"AG003","Sari
+Dewi",sari@example.com,abc,yes,2,north
+`
+
+func TestValidStruct_ValidCSV(t *testing.T) {
+	validtr := NewValidStruct(NewValidationMapper())
+	report, err := validtr.ValidCSV(strings.NewReader(agentCSV), AgentRow{})
+	if err != nil {
+		t.Fatalf("%s expected error nil got %s", failed, err.Error())
+	}
+
+	t.Log("\nTesting headers are mapped to fields")
+	{
+		if len(report.Rows) == 3 && strings.Join(report.Unmapped, ",") == "region" {
+			t.Logf("%s expected 3 rows and region unmapped", success)
+		} else {
+			t.Errorf("%s expected 3 rows and region unmapped got %d rows and %v", failed, len(report.Rows), report.Unmapped)
+		}
+
+		agent := report.Rows[0].Value.(*AgentRow)
+		if agent.Name == "Budi" && agent.Age == 30 && agent.Active && agent.Quota != nil && *agent.Quota == 1.5 {
+			t.Logf("%s expected converted and normalized values", success)
+		} else {
+			t.Errorf("%s expected converted and normalized values got %+v", failed, agent)
+		}
+	}
+
+	t.Log("\nTesting errors are reported by row and column")
+	{
+		expected := []string{
+			"row 3, column agent_code: Code has invalid format value",
+			"row 3, column name: name is required",
+			"row 3, column email: Email has invalid format value",
+			"row 3, column age: age must be at least 17",
+			`row 4, column age: invalid value abc: strconv.ParseInt: parsing "abc": invalid syntax`,
+			`row 4, column active: invalid value yes: strconv.ParseBool: parsing "yes": invalid syntax`,
+		}
+		errs := report.Errors()
+		got := make([]string, len(errs))
+		for i, err := range errs {
+			got[i] = err.Error()
+		}
+		if strings.Join(got, "\n") == strings.Join(expected, "\n") {
+			t.Logf("%s expected %d errors", success, len(expected))
+		} else {
+			t.Errorf("%s expected\n%s\ngot\n%s", failed, strings.Join(expected, "\n"), strings.Join(got, "\n"))
+		}
+		if !report.Valid() {
+			t.Logf("%s expected report not valid", success)
+		} else {
+			t.Errorf("%s expected report not valid", failed)
+		}
+	}
+
+	t.Log("\nTesting annotated csv")
+	{
+		var buf bytes.Buffer
+		if err := report.WriteCSV(&buf); err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+		lines := strings.Split(buf.String(), "\n")
+		if lines[0] == "agent_code,name,email,age,active,quota,region,errors" &&
+			lines[1] == `AG001," Budi ",budi@example.com,30,true,1.5,west,` &&
+			lines[2] == "AG02,,budi,16,false,,east,agent_code: Code has invalid format value; name: name is required; email: Email has invalid format value; age: age must be at least 17" {
+			t.Logf("%s expected errors column", success)
+		} else {
+			t.Errorf("%s expected errors column got\n%s", failed, buf.String())
+		}
+	}
+
+	t.Log("\nTesting empty file")
+	{
+		if _, err := validtr.ValidCSV(strings.NewReader(""), AgentRow{}); err != nil {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected error csv has no header row", failed)
+		}
+	}
+}
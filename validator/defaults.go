@@ -0,0 +1,131 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+// ApplyDefaults fills empty fields of input with the value in default tag, nested structs included.
+// Slice defaults are separated by comma, duration defaults use time.ParseDuration format,
+// and time defaults are either now, RFC3339 or DateLayout formatted. A nil pointer field with default tag
+// is allocated, for a pointer to struct the tag value is ignored, like `default:"{}"`.
+// input must be a pointer to struct, so the fields can be changed.
+func (s *ValidStruct) ApplyDefaults(input interface{}) error {
+	v := reflect.ValueOf(input)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("apply defaults only accept input type pointer to struct")
+	}
+
+	return s.applyDefaults(v.Elem())
+}
+
+func (s *ValidStruct) applyDefaults(v reflect.Value) error {
+	return walkStruct(v, func(parent, fv reflect.Value, ft reflect.StructField) error {
+		def, found := ft.Tag.Lookup("default")
+		if !found || !fv.CanSet() || !IsEmpty(fv.Interface()) {
+			return nil
+		}
+
+		target := fv
+		if fv.Kind() == reflect.Ptr {
+			target = reflect.New(fv.Type().Elem()).Elem()
+		}
+
+		if err := s.parseValue(target, def); err != nil {
+			return fmt.Errorf("invalid default value of %s: %s", ft.Name, err.Error())
+		}
+
+		if fv.Kind() == reflect.Ptr {
+			fv.Set(target.Addr())
+		}
+
+		return nil
+	})
+}
+
+// parseValue converts def, a default tag or a text cell, into the type of v and sets it
+func (s *ValidStruct) parseValue(v reflect.Value, def string) error {
+	if scanned, err := scanText(v, def); scanned {
+		return err // a sql.Scanner, like sql.NullString, converts the text itself
+	}
+
+	if isNestedStruct(v.Type()) {
+		return nil // fields of the struct are filled by their own default tag
+	}
+
+	if v.Type() == durationType {
+		d, err := time.ParseDuration(def)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	if v.Type() == timeType {
+		var (
+			tm  time.Time
+			err error
+		)
+		if def == "now" {
+			tm = time.Now()
+		} else if tm, err = time.Parse(time.RFC3339, def); err != nil {
+			if tm, err = time.Parse(s.DateLayout, def); err != nil {
+				return err
+			}
+		}
+		v.Set(reflect.ValueOf(tm))
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(def)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(def)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(def, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(def, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(def, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		splits := strings.Split(def, ",")
+		slice := reflect.MakeSlice(v.Type(), len(splits), len(splits))
+		for i, split := range splits {
+			if err := s.parseValue(slice.Index(i), strings.TrimSpace(split)); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
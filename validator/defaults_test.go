@@ -0,0 +1,80 @@
+package validator
+
+import (
+	"testing"
+	"time"
+)
+
+type Settings struct {
+	Name      string        `default:"guest"`
+	Retry     int           `default:"3"`
+	Ratio     float64       `default:"0.5"`
+	Port      uint16        `default:"8080"`
+	Enabled   bool          `default:"true"`
+	Timeout   time.Duration `default:"1m30s"`
+	CreatedAt time.Time     `default:"now"`
+	Roles     []string      `default:"admin, staff"`
+	Limit     *int          `default:"10"`
+	Queue     struct {
+		Size int `default:"100"`
+	}
+	Backup *struct {
+		Path string `default:"/tmp"`
+	} `default:"{}"`
+}
+
+func TestValidStruct_ApplyDefaults(t *testing.T) {
+	t.Log("\nTesting apply defaults to empty struct")
+	{
+		settings := Settings{}
+		validtr := NewValidStruct(NewValidationMapper())
+		if err := validtr.ApplyDefaults(&settings); err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+
+		if settings.Name == "guest" && settings.Retry == 3 && settings.Ratio == 0.5 && settings.Port == 8080 &&
+			settings.Enabled && settings.Timeout == 90*time.Second && !settings.CreatedAt.IsZero() &&
+			len(settings.Roles) == 2 && settings.Roles[1] == "staff" && settings.Limit != nil && *settings.Limit == 10 &&
+			settings.Queue.Size == 100 && settings.Backup != nil && settings.Backup.Path == "/tmp" {
+			t.Logf("%s expected defaults applied %+v", success, settings)
+		} else {
+			t.Errorf("%s expected defaults applied got %+v", failed, settings)
+		}
+	}
+
+	t.Log("\nTesting apply defaults keeps filled fields")
+	{
+		settings := Settings{Name: "admin", Retry: 1}
+		validtr := NewValidStruct(NewValidationMapper())
+		validtr.ApplyDefaults(&settings)
+
+		if settings.Name == "admin" && settings.Retry == 1 {
+			t.Logf("%s expected filled fields kept", success)
+		} else {
+			t.Errorf("%s expected name admin and retry 1 got %s and %d", failed, settings.Name, settings.Retry)
+		}
+	}
+
+	t.Log("\nTesting apply defaults with invalid default value")
+	{
+		input := struct {
+			Retry int `default:"three"`
+		}{}
+		validtr := NewValidStruct(NewValidationMapper())
+		if err := validtr.ApplyDefaults(&input); err != nil {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected error not nil", failed)
+		}
+	}
+
+	t.Log("\nTesting apply defaults on valid")
+	{
+		input := struct {
+			Status string `default:"active" valid:"funcVal:Required"`
+		}{}
+		validtr := NewValidStruct(NewValidationMapper())
+		validtr.ApplyDefaultsOnValid = true
+		checkErrorMessages(t, validtr.Valid(&input), []string{})
+	}
+}
@@ -0,0 +1,41 @@
+package validator
+
+import (
+	"strings"
+)
+
+// Errors is a list of validation errors usable as one error
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// ErrorsOrNil returns errs as Errors, or nil when errs is empty
+func ErrorsOrNil(errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return Errors(errs)
+}
+
+// FieldError is the error of one rule of a field, as returned by Valid and ValidMap. Field is the path of
+// the value, keys of nested structs or maps joined by dot like address.city, and Rule is the funcVal that failed.
+// Error returns the message of the rule only.
+type FieldError struct {
+	Field string
+	Rule  string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
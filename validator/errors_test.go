@@ -0,0 +1,49 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFieldError(t *testing.T) {
+	t.Log("\nTesting errors of Valid hold the field path and rule")
+	{
+		validtr := NewValidStruct(NewValidationMapper())
+		order := Order{Id: 1, Customer: Person{Name: "Jane", Email: "wrong"}, Shipping: &Person{}}
+
+		var got []string
+		for _, err := range validtr.Valid(order) {
+			fieldErr, ok := err.(*FieldError)
+			if !ok {
+				t.Fatalf("%s expected *FieldError got %T", failed, err)
+			}
+			got = append(got, fieldErr.Field+" "+fieldErr.Rule)
+		}
+
+		expected := "Customer.Email Email,Shipping.Name Required,Shipping.Email Required"
+		if strings.Join(got, ",") == expected {
+			t.Logf("%s expected %s", success, expected)
+		} else {
+			t.Errorf("%s expected %s got %s", failed, expected, strings.Join(got, ","))
+		}
+	}
+
+	t.Log("\nTesting Errors joins messages")
+	{
+		err := ErrorsOrNil([]error{&FieldError{Field: "a", Rule: "Required", Err: errorString("a is required")}, errorString("b is required")})
+		if err != nil && err.Error() == "a is required; b is required" {
+			t.Logf("%s expected %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected a is required; b is required got %v", failed, err)
+		}
+		if ErrorsOrNil(nil) == nil {
+			t.Logf("%s expected nil for no error", success)
+		} else {
+			t.Errorf("%s expected nil for no error", failed)
+		}
+	}
+}
+
+type errorString string
+
+func (e errorString) Error() string { return string(e) }
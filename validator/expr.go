@@ -0,0 +1,969 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// MaxExprLength is the longest expression accepted by an expr rule
+const MaxExprLength = 1024
+
+// Expr is a parsed expression of an expr rule, like expr:Discount <= Price * 0.5. An expression reads the fields of the
+// struct holding the field of the rule, by field name or json name, and nested paths like Address.Zip. A path through
+// a slice gives the collection of its elements, like Items.Qty, for the aggregates sum, avg, min, max, count and len.
+//
+// Values are numbers, strings in single quotes, true, false, nil, times and durations like 30d, 12h or 1h30m.
+// Operators are + - * / %, == != < <= > >=, && || ! and parentheses. A time minus a time is a duration,
+// and a time plus a duration is a time. Functions are len, sum, avg, min, max, count, abs, now, days and hours.
+// An expression can only read exported fields, it calls no method and has no loop, so it always ends.
+//
+// nil, an unset pointer and a division by zero have no value: an operator or abs, days and hours applied to no value
+// give no value, a comparison with it is false and && and || take it as false. An expr rule giving no value fails.
+type Expr struct {
+	text  string
+	root  exprNode
+	paths [][]string
+}
+
+// ParseExpr parses text, the expression of an expr rule
+func ParseExpr(text string) (*Expr, error) {
+	if len(text) > MaxExprLength {
+		return nil, fmt.Errorf("expression is longer than %d bytes", MaxExprLength)
+	}
+
+	p := &exprParser{text: text}
+	if err := p.tokenize(); err != nil {
+		return nil, fmt.Errorf("invalid expression %s: %s", text, err.Error())
+	}
+	root, err := p.parseOr()
+	if err == nil && p.peek().kind != exprEOF {
+		err = fmt.Errorf("unexpected %s at %d", p.peek().text, p.peek().pos)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid expression %s: %s", text, err.Error())
+	}
+
+	return &Expr{text: text, root: root, paths: p.paths}, nil
+}
+
+func (e *Expr) String() string {
+	return e.text
+}
+
+// Fields returns the field paths read by e, like Price or Items.Qty
+func (e *Expr) Fields() []string {
+	fields := make([]string, 0, len(e.paths))
+	for _, path := range e.paths {
+		fields = append(fields, strings.Join(path, "."))
+	}
+	return fields
+}
+
+// Check reports a field path of e not found in struct type t. Paths through maps and interfaces are only resolved on Eval.
+func (e *Expr) Check(t reflect.Type) error {
+	for _, path := range e.paths {
+		if err := checkExprPath(t, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Eval evaluates e against root, the struct or string keyed map holding the fields read by e
+func (e *Expr) Eval(root interface{}) (interface{}, error) {
+	return e.eval(reflect.ValueOf(root))
+}
+
+func (e *Expr) eval(root reflect.Value) (interface{}, error) {
+	return e.root.eval(&exprEnv{root: root, now: time.Now()})
+}
+
+// exprKey is the cache key of an expression compiled for the struct or map type holding its fields
+type exprKey struct {
+	t    reflect.Type
+	text string
+}
+
+type compiledExpr struct {
+	expr *Expr
+	err  error
+}
+
+// compiledExpr returns text parsed and checked against t, once per type and text
+func (s *ValidStruct) compiledExpr(t reflect.Type, text string) (*Expr, error) {
+	key := exprKey{t: t, text: text}
+
+	s.exprLock.RLock()
+	compiled, found := s.exprs[key]
+	s.exprLock.RUnlock()
+	if found {
+		return compiled.expr, compiled.err
+	}
+
+	expr, err := ParseExpr(text)
+	if err == nil && t.Kind() == reflect.Struct {
+		err = expr.Check(t)
+	}
+
+	s.exprLock.Lock()
+	if s.exprs == nil {
+		s.exprs = make(map[exprKey]compiledExpr)
+	}
+	s.exprs[key] = compiledExpr{expr: expr, err: err}
+	s.exprLock.Unlock()
+
+	return expr, err
+}
+
+// runExpr runs the expr rule of dtag, parent is the struct or map holding fv. The expression must give true.
+func (s *ValidStruct) runExpr(locale string, dtag *dataTag, parent, fv reflect.Value, typeName, fieldName, keyName string) error {
+	expr, err := s.compiledExpr(parent.Type(), dtag.expr)
+	if err != nil {
+		return err
+	}
+
+	result, err := expr.eval(parent)
+	if err != nil {
+		return fmt.Errorf("expression %s failed: %s", dtag.expr, err.Error())
+	}
+	passed, ok := result.(bool)
+	if !ok && result != nil {
+		return fmt.Errorf("expression %s gives %s, expected a boolean", dtag.expr, exprTypeName(result))
+	}
+	if passed {
+		return nil
+	}
+
+	if message := s.errorMessageKey(locale, typeName, fieldName, keyName, fv, dtag, "Expr", map[string]string{"expr": dtag.expr}); message != "" {
+		return errors.New(message)
+	}
+	return fmt.Errorf("%s must satisfy %s", keyName, dtag.expr)
+}
+
+// checkExprPath reports a part of path not found from type t
+func checkExprPath(t reflect.Type, path []string) error {
+	for i := 0; i < len(path); {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Array:
+			t = t.Elem()
+			continue
+		case reflect.Map, reflect.Interface:
+			return nil
+		case reflect.Struct:
+			ft, found := exprField(t, path[i])
+			if !found {
+				return fmt.Errorf("field %s is not found in %s", path[i], t)
+			}
+			t = ft.Type
+			i++
+			continue
+		}
+		return fmt.Errorf("%s is not a field of %s", path[i], t)
+	}
+	return nil
+}
+
+// exprField finds the exported field of struct type t named key, by its name, json name or as a promoted field
+func exprField(t reflect.Type, key string) (reflect.StructField, bool) {
+	ft, found := fieldByKey(t, key)
+	if !found {
+		ft, found = t.FieldByName(key)
+	}
+	if !found || ft.PkgPath != "" {
+		return reflect.StructField{}, false
+	}
+	return ft, true
+}
+
+// lookupExprPath reads path from v, a path through a slice gives the collection of its elements
+func lookupExprPath(v reflect.Value, path []string) (interface{}, error) {
+	for v.IsValid() && (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return nil, nil
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if len(path) == 0 {
+		return exprValue(v)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == timeType {
+			break
+		}
+		ft, found := exprField(v.Type(), path[0])
+		if !found {
+			return nil, fmt.Errorf("field %s is not found in %s", path[0], v.Type())
+		}
+		return lookupExprPath(v.FieldByIndex(ft.Index), path[1:])
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		return lookupExprPath(mapEntry(v, path[0]), path[1:])
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, err := lookupExprPath(v.Index(i), path)
+			if err != nil {
+				return nil, err
+			}
+			if nested, ok := item.([]interface{}); ok {
+				items = append(items, nested...)
+			} else {
+				items = append(items, item)
+			}
+		}
+		return items, nil
+	}
+
+	return nil, fmt.Errorf("%s is not a field of %s", path[0], v.Type())
+}
+
+// exprValue converts v into a value of the expression language: float64, string, bool, time.Time,
+// time.Duration, []interface{} or nil
+func exprValue(v reflect.Value) (interface{}, error) {
+	if !v.IsValid() {
+		return nil, nil
+	}
+	if v.CanInterface() && v.Type().Implements(valuerType) {
+		if v.Kind() == reflect.Ptr && v.IsNil() {
+			return nil, nil
+		}
+		underlying := Underlying(v.Interface())
+		if underlying == nil {
+			return nil, nil
+		}
+		v = reflect.ValueOf(underlying)
+	}
+
+	switch {
+	case v.Type() == durationType:
+		return time.Duration(v.Int()), nil
+	case v.Type() == timeType:
+		return v.Interface().(time.Time), nil
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if v.IsNil() {
+			return nil, nil
+		}
+		return exprValue(v.Elem())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Slice, reflect.Array:
+		items := make([]interface{}, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			item, err := exprValue(v.Index(i))
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case reflect.Struct:
+		return exprStruct{v.Type()}, nil
+	}
+
+	return nil, fmt.Errorf("value of type %s is not supported in expressions", v.Type())
+}
+
+// exprStruct is a struct value in an expression. It is only read through its fields,
+// counted or compared with nil, like count(Lines) or Address != nil.
+type exprStruct struct {
+	typ reflect.Type
+}
+
+// exprTypeName names the type of a value of the expression language in error messages
+func exprTypeName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "nil"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case time.Time:
+		return "time"
+	case time.Duration:
+		return "duration"
+	case []interface{}:
+		return "collection"
+	case exprStruct:
+		return v.typ.String()
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+type exprEnv struct {
+	root reflect.Value
+	now  time.Time
+}
+
+type exprNode interface {
+	eval(env *exprEnv) (interface{}, error)
+}
+
+type exprLiteral struct {
+	value interface{}
+}
+
+func (n *exprLiteral) eval(env *exprEnv) (interface{}, error) {
+	return n.value, nil
+}
+
+type exprPath struct {
+	path []string
+}
+
+func (n *exprPath) eval(env *exprEnv) (interface{}, error) {
+	return lookupExprPath(env.root, n.path)
+}
+
+type exprUnary struct {
+	op      string
+	operand exprNode
+}
+
+func (n *exprUnary) eval(env *exprEnv) (interface{}, error) {
+	value, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return nil, nil // no value, like an unset pointer
+	case bool:
+		if n.op == "!" {
+			return !v, nil
+		}
+	case float64:
+		if n.op == "-" {
+			return -v, nil
+		}
+	case time.Duration:
+		if n.op == "-" {
+			return -v, nil
+		}
+	}
+	return nil, fmt.Errorf("can not apply %s to %s", n.op, exprTypeName(value))
+}
+
+type exprBinary struct {
+	op          string
+	left, right exprNode
+}
+
+func (n *exprBinary) eval(env *exprEnv) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "&&" || n.op == "||" {
+		l, ok := left.(bool)
+		if left == nil {
+			l, ok = false, true // no value is not satisfied
+		}
+		if !ok {
+			return nil, fmt.Errorf("can not apply %s to %s", n.op, exprTypeName(left))
+		}
+		if (n.op == "&&" && !l) || (n.op == "||" && l) {
+			return l, nil
+		}
+		right, err := n.right.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		if r, ok := right.(bool); ok {
+			return r, nil
+		}
+		if right == nil {
+			return false, nil
+		}
+		return nil, fmt.Errorf("can not apply %s to %s", n.op, exprTypeName(right))
+	}
+
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return exprEqual(left, right), nil
+	case "!=":
+		return !exprEqual(left, right), nil
+	case "<", "<=", ">", ">=":
+		return exprCompare(n.op, left, right)
+	}
+	return exprArithmetic(n.op, left, right)
+}
+
+func exprEqual(left, right interface{}) bool {
+	switch l := left.(type) {
+	case time.Time:
+		r, ok := right.(time.Time)
+		return ok && l.Equal(r)
+	case []interface{}:
+		r, ok := right.([]interface{})
+		if !ok || len(l) != len(r) {
+			return false
+		}
+		for i := range l {
+			if !exprEqual(l[i], r[i]) {
+				return false
+			}
+		}
+		return true
+	case exprStruct:
+		return false
+	}
+	switch right.(type) {
+	case []interface{}, exprStruct:
+		return false
+	}
+	return left == right
+}
+
+func exprCompare(op string, left, right interface{}) (bool, error) {
+	if left == nil || right == nil {
+		return false, nil // nothing is ordered with no value
+	}
+
+	var cmp int
+	switch l := left.(type) {
+	case float64:
+		r, ok := right.(float64)
+		if !ok {
+			break
+		}
+		cmp = compareOrdered(l < r, l > r)
+		return compareResult(op, cmp), nil
+	case string:
+		r, ok := right.(string)
+		if !ok {
+			break
+		}
+		cmp = strings.Compare(l, r)
+		return compareResult(op, cmp), nil
+	case time.Duration:
+		r, ok := right.(time.Duration)
+		if !ok {
+			break
+		}
+		cmp = compareOrdered(l < r, l > r)
+		return compareResult(op, cmp), nil
+	case time.Time:
+		r, ok := right.(time.Time)
+		if !ok {
+			break
+		}
+		cmp = compareOrdered(l.Before(r), l.After(r))
+		return compareResult(op, cmp), nil
+	}
+	return false, fmt.Errorf("can not compare %s with %s", exprTypeName(left), exprTypeName(right))
+}
+
+func compareOrdered(less, greater bool) int {
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
+
+func compareResult(op string, cmp int) bool {
+	switch op {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	}
+	return cmp >= 0
+}
+
+func exprArithmetic(op string, left, right interface{}) (interface{}, error) {
+	if left == nil || right == nil {
+		return nil, nil
+	}
+
+	switch l := left.(type) {
+	case float64:
+		switch r := right.(type) {
+		case float64:
+			switch op {
+			case "+":
+				return l + r, nil
+			case "-":
+				return l - r, nil
+			case "*":
+				return l * r, nil
+			case "/", "%":
+				if r == 0 {
+					return nil, nil // a division by zero has no value
+				}
+				if op == "%" {
+					return math.Mod(l, r), nil
+				}
+				return l / r, nil
+			}
+		case time.Duration:
+			if op == "*" {
+				return time.Duration(l * float64(r)), nil
+			}
+		}
+	case string:
+		if r, ok := right.(string); ok && op == "+" {
+			return l + r, nil
+		}
+	case time.Time:
+		switch r := right.(type) {
+		case time.Time:
+			if op == "-" {
+				return l.Sub(r), nil
+			}
+		case time.Duration:
+			switch op {
+			case "+":
+				return l.Add(r), nil
+			case "-":
+				return l.Add(-r), nil
+			}
+		}
+	case time.Duration:
+		switch r := right.(type) {
+		case time.Duration:
+			switch op {
+			case "+":
+				return l + r, nil
+			case "-":
+				return l - r, nil
+			case "/":
+				if r == 0 {
+					return nil, nil
+				}
+				return float64(l) / float64(r), nil
+			}
+		case float64:
+			switch op {
+			case "*":
+				return time.Duration(float64(l) * r), nil
+			case "/":
+				if r == 0 {
+					return nil, nil
+				}
+				return time.Duration(float64(l) / r), nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("can not apply %s to %s and %s", op, exprTypeName(left), exprTypeName(right))
+}
+
+type exprCall struct {
+	name string
+	args []exprNode
+}
+
+// exprFuncs are the functions of the expression language with their number of arguments
+var exprFuncs = map[string]int{
+	"len":   1,
+	"sum":   1,
+	"avg":   1,
+	"min":   1,
+	"max":   1,
+	"count": 1,
+	"abs":   1,
+	"days":  1,
+	"hours": 1,
+	"now":   0,
+}
+
+func (n *exprCall) eval(env *exprEnv) (interface{}, error) {
+	if n.name == "now" {
+		return env.now, nil
+	}
+
+	arg, err := n.args[0].eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.name {
+	case "len":
+		switch v := arg.(type) {
+		case nil:
+			return float64(0), nil
+		case string:
+			return float64(utf8.RuneCountInString(v)), nil
+		case []interface{}:
+			return float64(len(v)), nil
+		}
+	case "abs":
+		switch v := arg.(type) {
+		case nil:
+			return nil, nil
+		case float64:
+			return math.Abs(v), nil
+		case time.Duration:
+			if v < 0 {
+				return -v, nil
+			}
+			return v, nil
+		}
+	case "days", "hours":
+		if arg == nil {
+			return nil, nil
+		}
+		if v, ok := arg.(time.Duration); ok {
+			if n.name == "days" {
+				return v.Hours() / 24, nil
+			}
+			return v.Hours(), nil
+		}
+	default:
+		return aggregate(n.name, collection(arg))
+	}
+
+	return nil, fmt.Errorf("%s does not take %s", n.name, exprTypeName(arg))
+}
+
+// collection returns value as the items of an aggregate, a single value is a collection of one item
+func collection(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		return v
+	}
+	return []interface{}{value}
+}
+
+// aggregate runs sum, avg, min, max or count over items. count counts the items that are not empty, like true booleans.
+// min and max of no item is nil, sum and avg of no item is 0.
+func aggregate(name string, items []interface{}) (interface{}, error) {
+	if name == "count" {
+		count := 0
+		for _, item := range items {
+			if item != nil && !IsEmpty(item) {
+				count++
+			}
+		}
+		return float64(count), nil
+	}
+
+	var result interface{}
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		if result == nil {
+			result = item
+			continue
+		}
+
+		var err error
+		switch name {
+		case "sum", "avg":
+			result, err = exprArithmetic("+", result, item)
+		case "min", "max":
+			var less bool
+			less, err = exprCompare("<", item, result)
+			if err == nil && less == (name == "min") {
+				result = item
+			}
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err.Error())
+		}
+	}
+
+	switch {
+	case result == nil && (name == "sum" || name == "avg"):
+		return float64(0), nil
+	case result == nil:
+		return nil, nil
+	case name == "avg":
+		return exprArithmetic("/", result, float64(countValues(items)))
+	case name == "sum" || name == "min" || name == "max":
+		switch result.(type) {
+		case float64, time.Duration:
+		case time.Time, string:
+			if name == "sum" {
+				return nil, fmt.Errorf("sum does not take %s", exprTypeName(result))
+			}
+		default:
+			return nil, fmt.Errorf("%s does not take %s", name, exprTypeName(result))
+		}
+	}
+	return result, nil
+}
+
+func countValues(items []interface{}) int {
+	count := 0
+	for _, item := range items {
+		if item != nil {
+			count++
+		}
+	}
+	return count
+}
+
+const (
+	exprEOF = iota
+	exprNumber
+	exprDuration
+	exprString
+	exprIdent
+	exprOp
+)
+
+type exprToken struct {
+	kind  int
+	text  string
+	pos   int
+	value interface{}
+}
+
+type exprParser struct {
+	text   string
+	tokens []exprToken
+	next   int
+	paths  [][]string
+}
+
+var (
+	exprOps           = []string{"==", "!=", "<=", ">=", "&&", "||", "<", ">", "!", "+", "-", "*", "/", "%", "(", ")"}
+	exprDurationRegex = regexp.MustCompile(`^(?:[0-9]+(?:\.[0-9]+)?(?:ns|us|ms|s|m|h|d|w))+$`)
+	exprDurationPart  = regexp.MustCompile(`([0-9]+(?:\.[0-9]+)?)(ns|us|ms|s|m|h|d|w)`)
+	exprUnits         = map[string]time.Duration{
+		"ns": time.Nanosecond, "us": time.Microsecond, "ms": time.Millisecond, "s": time.Second,
+		"m": time.Minute, "h": time.Hour, "d": 24 * time.Hour, "w": 7 * 24 * time.Hour,
+	}
+)
+
+func (p *exprParser) tokenize() error {
+	text := p.text
+	for i := 0; i < len(text); {
+		c := rune(text[i])
+		switch {
+		case c == ' ' || c == '\t':
+			i++
+		case unicode.IsDigit(c):
+			start := i
+			for i < len(text) && (unicode.IsDigit(rune(text[i])) || text[i] == '.' || unicode.IsLetter(rune(text[i]))) {
+				i++
+			}
+			word := text[start:i]
+			if number, err := strconv.ParseFloat(word, 64); err == nil {
+				p.tokens = append(p.tokens, exprToken{kind: exprNumber, text: word, pos: start, value: number})
+				continue
+			}
+			if !exprDurationRegex.MatchString(word) {
+				return fmt.Errorf("invalid number %s at %d", word, start)
+			}
+			var duration time.Duration
+			for _, part := range exprDurationPart.FindAllStringSubmatch(word, -1) {
+				amount, _ := strconv.ParseFloat(part[1], 64)
+				duration += time.Duration(amount * float64(exprUnits[part[2]]))
+			}
+			p.tokens = append(p.tokens, exprToken{kind: exprDuration, text: word, pos: start, value: duration})
+		case c == '\'':
+			end := strings.IndexByte(text[i+1:], '\'')
+			if end < 0 {
+				return fmt.Errorf("quote at %d is not closed", i)
+			}
+			p.tokens = append(p.tokens, exprToken{kind: exprString, text: text[i : i+end+2], pos: i, value: text[i+1 : i+1+end]})
+			i += end + 2
+		case c == '_' || unicode.IsLetter(c):
+			start := i
+			for i < len(text) && (text[i] == '_' || text[i] == '.' || unicode.IsLetter(rune(text[i])) || unicode.IsDigit(rune(text[i]))) {
+				i++
+			}
+			p.tokens = append(p.tokens, exprToken{kind: exprIdent, text: text[start:i], pos: start})
+		default:
+			matched := false
+			for _, op := range exprOps {
+				if strings.HasPrefix(text[i:], op) {
+					p.tokens = append(p.tokens, exprToken{kind: exprOp, text: op, pos: i})
+					i += len(op)
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return fmt.Errorf("unexpected %q at %d", c, i)
+			}
+		}
+	}
+	p.tokens = append(p.tokens, exprToken{kind: exprEOF, text: "end", pos: len(text)})
+	return nil
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.next]
+}
+
+// accept consumes the next token when it is one of ops
+func (p *exprParser) accept(ops ...string) (string, bool) {
+	token := p.peek()
+	if token.kind != exprOp {
+		return "", false
+	}
+	for _, op := range ops {
+		if token.text == op {
+			p.next++
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *exprParser) parseOr() (exprNode, error) {
+	return p.parseLeft(p.parseAnd, "||")
+}
+
+func (p *exprParser) parseAnd() (exprNode, error) {
+	return p.parseLeft(p.parseComparison, "&&")
+}
+
+func (p *exprParser) parseComparison() (exprNode, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if op, ok := p.accept("==", "!=", "<=", ">=", "<", ">"); ok {
+		right, err := p.parseAdditive()
+		if err != nil {
+			return nil, err
+		}
+		return &exprBinary{op: op, left: left, right: right}, nil
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAdditive() (exprNode, error) {
+	return p.parseLeft(p.parseMultiplicative, "+", "-")
+}
+
+func (p *exprParser) parseMultiplicative() (exprNode, error) {
+	return p.parseLeft(p.parseUnary, "*", "/", "%")
+}
+
+// parseLeft parses left associative operators ops between operands parsed by next
+func (p *exprParser) parseLeft(next func() (exprNode, error), ops ...string) (exprNode, error) {
+	left, err := next()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op, ok := p.accept(ops...)
+		if !ok {
+			return left, nil
+		}
+		right, err := next()
+		if err != nil {
+			return nil, err
+		}
+		left = &exprBinary{op: op, left: left, right: right}
+	}
+}
+
+func (p *exprParser) parseUnary() (exprNode, error) {
+	if op, ok := p.accept("!", "-"); ok {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &exprUnary{op: op, operand: operand}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (exprNode, error) {
+	token := p.peek()
+	p.next++
+
+	switch token.kind {
+	case exprNumber, exprDuration, exprString:
+		return &exprLiteral{value: token.value}, nil
+	case exprIdent:
+		switch token.text {
+		case "true", "false":
+			return &exprLiteral{value: token.text == "true"}, nil
+		case "nil", "null":
+			return &exprLiteral{value: nil}, nil
+		}
+		if _, ok := p.accept("("); ok {
+			return p.parseCall(token)
+		}
+		path := strings.Split(token.text, ".")
+		for _, part := range path {
+			if part == "" {
+				return nil, fmt.Errorf("invalid field path %s at %d", token.text, token.pos)
+			}
+		}
+		p.paths = append(p.paths, path)
+		return &exprPath{path: path}, nil
+	case exprOp:
+		if token.text == "(" {
+			node, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := p.accept(")"); !ok {
+				return nil, fmt.Errorf("parenthesis at %d is not closed", token.pos)
+			}
+			return node, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected %s at %d", token.text, token.pos)
+}
+
+func (p *exprParser) parseCall(name exprToken) (exprNode, error) {
+	arity, found := exprFuncs[name.text]
+	if !found {
+		return nil, fmt.Errorf("unknown function %s at %d", name.text, name.pos)
+	}
+
+	call := &exprCall{name: name.text}
+	if arity == 1 {
+		arg, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+	}
+	if _, ok := p.accept(")"); !ok {
+		return nil, fmt.Errorf("%s takes %d argument, expected ) at %d", name.text, arity, p.peek().pos)
+	}
+	return call, nil
+}
@@ -0,0 +1,201 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+type OrderLine struct {
+	Sku string  `json:"sku"`
+	Qty int     `json:"qty"`
+	Fee float64 `json:"fee"`
+}
+
+type Booking struct {
+	Price     float64     `json:"price"`
+	Discount  float64     `json:"discount" valid:"expr:Discount <= Price * 0.5"`
+	StartDate time.Time   `json:"start_date"`
+	EndDate   time.Time   `json:"end_date" valid:"expr:EndDate - StartDate <= 30d && EndDate > StartDate,errorMessage:{field} must be within 30 days after start_date"`
+	Lines     []OrderLine `json:"lines" valid:"expr:sum(lines.qty) > 0 && count(Lines.Sku) == len(Lines)"`
+	Address   Address     `json:"address"`
+	Courier   string      `json:"courier" valid:"expr:courier != 'pickup' || len(address.Street) == 0"`
+	Voucher   string      `json:"voucher" valid:"sensitive;expr:len(Voucher) == 8"`
+}
+
+type Quota struct {
+	Limit *int    `json:"limit" valid:"expr:Limit > 1"`
+	Spare *int    `json:"spare" valid:"expr:Spare == nil || Spare > 1"`
+	Total float64 `json:"total"`
+	Ratio float64 `json:"ratio" valid:"expr:Total / Ratio > 2"`
+	Name  string  `json:"name" valid:"expr:Name != 'a,b;c',errorMessage:{field} isn't available"`
+}
+
+func TestExpr(t *testing.T) {
+	validtr := NewValidStruct(NewValidationMapper())
+	start := time.Date(2021, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	valid := Booking{
+		Price:     200,
+		Discount:  100,
+		StartDate: start,
+		EndDate:   start.Add(10 * 24 * time.Hour),
+		Lines:     []OrderLine{{Sku: "A-1", Qty: 1}, {Sku: "B-2", Qty: 2}},
+		Address:   Address{Street: "Jl. Sudirman 1"},
+		Courier:   "express",
+		Voucher:   "ABCD1234",
+	}
+
+	t.Log("\nTesting expr rules passing")
+	{
+		if errs := validtr.Valid(valid); len(errs) == 0 {
+			t.Logf("%s expected no error", success)
+		} else {
+			t.Errorf("%s expected no error got %v", failed, errs)
+		}
+	}
+
+	t.Log("\nTesting expr rules failing")
+	{
+		booking := valid
+		booking.Discount = 150
+		booking.EndDate = start.Add(45 * 24 * time.Hour)
+		booking.Lines = []OrderLine{{Qty: 0}}
+		booking.Courier = "pickup"
+		booking.Voucher = "SHORT"
+
+		checkFieldErrors(t, validtr.ValidLocale(booking, "en"), []string{
+			"discount Expr: discount must satisfy Discount <= Price * 0.5",
+			"end_date Expr: end_date must be within 30 days after start_date",
+			"lines Expr: lines must satisfy sum(lines.qty) > 0 && count(Lines.Sku) == len(Lines)",
+			"courier Expr: courier must satisfy courier != 'pickup' || len(address.Street) == 0",
+			"voucher Expr: voucher must satisfy len(Voucher) == 8",
+		})
+
+		errs := validtr.ValidLocale(booking, "id")
+		if len(errs) == 5 && errs[0].Error() == "discount harus memenuhi Discount <= Price * 0.5" {
+			t.Logf("%s expected %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected discount harus memenuhi Discount <= Price * 0.5 got %v", failed, errs)
+		}
+	}
+
+	t.Log("\nTesting expressions compiled once per type")
+	{
+		validtr.Valid(valid)
+		validtr.exprLock.RLock()
+		compiled := len(validtr.exprs)
+		validtr.exprLock.RUnlock()
+		if compiled == 5 {
+			t.Logf("%s expected 5 compiled expressions", success)
+		} else {
+			t.Errorf("%s expected 5 compiled expressions got %d", failed, compiled)
+		}
+	}
+
+	t.Log("\nTesting unset values and quoted separators")
+	{
+		checkFieldErrors(t, validtr.Valid(Quota{Total: 10, Name: "a,b;c"}), []string{
+			"limit Expr: limit must satisfy Limit > 1",
+			"ratio Expr: ratio must satisfy Total / Ratio > 2",
+			"name Expr: name isn't available",
+		})
+
+		limit := 2
+		checkFieldErrors(t, validtr.Valid(Quota{Limit: &limit, Total: 10, Ratio: 2, Name: "a"}), nil)
+
+		rules := ParseRules("expr:Name != 'a,b;c';funcVal:AcceptedValues:'a,b'|Email,errorMessage:{field} isn't valid")
+		if len(rules) == 2 && rules[0].Expr == "Name != 'a,b;c'" && rules[1].FuncVal == "AcceptedValues:'a,b'|Email" && rules[1].ErrorMessage == "{field} isn't valid" {
+			t.Logf("%s expected quoted separators to be kept", success)
+		} else {
+			t.Errorf("%s expected quoted separators to be kept got %+v", failed, rules)
+		}
+	}
+
+	t.Log("\nTesting expr rules of ValidMap and RuleBuilder")
+	{
+		rules := map[string]string{"max": "expr:max >= min"}
+		if errs := validtr.ValidMap(map[string]interface{}{"min": 5, "max": 3}, rules); len(errs) == 1 && errs[0].Error() == "max must satisfy max >= min" {
+			t.Logf("%s expected %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected max must satisfy max >= min got %v", failed, errs)
+		}
+
+		builder := NewRuleBuilder()
+		builder.Field("Discount").Expr("Discount < Price").Message("{field} is too high")
+		if built := builder.Rules()["Discount"]; built == "expr:Discount < Price,errorMessage:{field} is too high" && ParseRules(built)[0].Expr == "Discount < Price" {
+			t.Logf("%s expected rules %s", success, built)
+		} else {
+			t.Errorf("%s expected expr rule got %s", failed, built)
+		}
+	}
+
+	t.Log("\nTesting invalid expressions")
+	{
+		errs := validtr.ValidMap(map[string]interface{}{"code": "x"}, map[string]string{"code": "expr:len(code)"})
+		if len(errs) == 1 && errs[0].Error() == "expression len(code) gives number, expected a boolean" {
+			t.Logf("%s expected %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected a boolean error got %v", failed, errs)
+		}
+
+		validtr.RegisterType(Booking{})
+		err := validtr.ApplyRuleConfig(&RuleConfig{Types: map[string]map[string]string{"Booking": {"discount": "expr:Discount <= Total"}}})
+		if err != nil && strings.Contains(err.Error(), "field Total is not found") {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected error of field Total got %v", failed, err)
+		}
+
+		for _, text := range []string{"Price <=", "(Price > 1", "Price > 'a", "size(Lines) > 1", "Price > 3x", "Price @ 2"} {
+			if _, err := ParseExpr(text); err != nil {
+				t.Logf("%s expected error %s", success, err.Error())
+			} else {
+				t.Errorf("%s expected error parsing %s", failed, text)
+			}
+		}
+	}
+
+	t.Log("\nTesting evaluating expressions")
+	{
+		booking := valid
+		booking.Lines = append(booking.Lines, OrderLine{Sku: "C-3", Qty: 4, Fee: 1.5})
+		cases := map[string]interface{}{
+			"sum(Lines.Qty)":                      float64(7),
+			"avg(Lines.Qty)":                      float64(7) / 3,
+			"max(Lines.Qty) - min(Lines.Qty)":     float64(3),
+			"count(Lines.Fee)":                    float64(1),
+			"days(EndDate - StartDate)":           float64(10),
+			"hours(-(StartDate - EndDate)) / 24":  float64(10),
+			"StartDate + 10d == EndDate":          true,
+			"Price % 3 == 2 && !(Discount > 100)": true,
+			"address.Street + '!'":                "Jl. Sudirman 1!",
+			"EndDate < now() && abs(-2) == 2":     true,
+			"max(Lines.Sku)":                      "C-3",
+			"count(Lines) == 3 && Address != nil": true,
+			"Price / 0 > 1 || Price / 0 <= 1":     false,
+			"Price / 0 == nil && abs(nil) == nil": true,
+		}
+		for text, expected := range cases {
+			expr, err := ParseExpr(text)
+			if err != nil {
+				t.Errorf("%s expected error nil parsing %s got %s", failed, text, err.Error())
+				continue
+			}
+			if result, err := expr.Eval(booking); err == nil && result == expected {
+				t.Logf("%s expected %s to give %v", success, text, expected)
+			} else {
+				t.Errorf("%s expected %s to give %v got %v and %v", failed, text, expected, result, err)
+			}
+		}
+
+		for _, text := range []string{"Price + 'a' == 1", "sum(Lines.Sku) > 1", "StartDate < 3"} {
+			expr, _ := ParseExpr(text)
+			if _, err := expr.Eval(booking); err != nil {
+				t.Logf("%s expected error %s", success, err.Error())
+			} else {
+				t.Errorf("%s expected error evaluating %s", failed, text)
+			}
+		}
+	}
+}
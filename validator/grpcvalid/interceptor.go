@@ -0,0 +1,135 @@
+// Package grpcvalid validates the messages of gRPC servers with validator.ValidStruct in unary and stream interceptors.
+// An invalid request fails with codes.InvalidArgument and an errdetails.BadRequest listing the field violations.
+package grpcvalid
+
+import (
+	"context"
+	"reflect"
+	"strings"
+
+	"github.com/zibilal/structiterator/validator"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Interceptor validates the messages received by a server, and optionally the messages it sends
+type Interceptor struct {
+	Validator *validator.ValidStruct
+	// Locale overrides the locale Validator.AcceptLocale picks from the accept-language metadata of a call
+	Locale string
+	// ValidateResponses validates the messages sent by the server too, an invalid one is not sent and fails the call with codes.Internal
+	ValidateResponses bool
+}
+
+// NewInterceptor creates an Interceptor validating with v
+func NewInterceptor(v *validator.ValidStruct) *Interceptor {
+	return &Interceptor{Validator: v}
+}
+
+// DefaultInterceptor is used by UnaryServerInterceptor and StreamServerInterceptor
+var DefaultInterceptor = NewInterceptor(validator.NewValidStruct(validator.NewValidationMapper()))
+
+// UnaryServerInterceptor validates unary requests with DefaultInterceptor
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return DefaultInterceptor.Unary()
+}
+
+// StreamServerInterceptor validates stream messages with DefaultInterceptor
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return DefaultInterceptor.Stream()
+}
+
+// Unary returns a server interceptor validating the request before calling the handler
+func (i *Interceptor) Unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if errs := i.valid(ctx, req); len(errs) > 0 {
+			return nil, InvalidArgument(errs).Err()
+		}
+
+		resp, err := handler(ctx, req)
+		if err != nil || !i.ValidateResponses {
+			return resp, err
+		}
+		if errs := i.valid(ctx, resp); len(errs) > 0 {
+			return nil, invalidResponse(info.FullMethod)
+		}
+		return resp, nil
+	}
+}
+
+// Stream returns a server interceptor validating every message received by the handler
+func (i *Interceptor) Stream() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, &validStream{ServerStream: ss, interceptor: i, method: info.FullMethod})
+	}
+}
+
+type validStream struct {
+	grpc.ServerStream
+	interceptor *Interceptor
+	method      string
+}
+
+func (s *validStream) RecvMsg(m interface{}) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if errs := s.interceptor.valid(s.Context(), m); len(errs) > 0 {
+		return InvalidArgument(errs).Err()
+	}
+	return nil
+}
+
+func (s *validStream) SendMsg(m interface{}) error {
+	if s.interceptor.ValidateResponses {
+		if errs := s.interceptor.valid(s.Context(), m); len(errs) > 0 {
+			return invalidResponse(s.method)
+		}
+	}
+	return s.ServerStream.SendMsg(m)
+}
+
+// valid validates msg when it is a struct or a pointer to struct, other messages have no rules
+func (i *Interceptor) valid(ctx context.Context, msg interface{}) []error {
+	v := reflect.ValueOf(msg)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+
+	locale := i.Locale
+	if locale == "" {
+		md, _ := metadata.FromIncomingContext(ctx)
+		locale = i.Validator.AcceptLocale(strings.Join(md.Get("accept-language"), ","))
+	}
+	return i.Validator.ValidLocaleContext(ctx, msg, locale)
+}
+
+// InvalidArgument creates a codes.InvalidArgument status with an errdetails.BadRequest holding a field violation for each
+// of errs, the field of a *validator.FieldError is kept and other errors have no field
+func InvalidArgument(errs []error) *status.Status {
+	badRequest := &errdetails.BadRequest{}
+	for _, err := range errs {
+		violation := &errdetails.BadRequest_FieldViolation{Description: err.Error()}
+		if fieldErr, ok := err.(*validator.FieldError); ok {
+			violation.Field = fieldErr.Field
+			violation.Reason = fieldErr.Rule
+		}
+		badRequest.FieldViolations = append(badRequest.FieldViolations, violation)
+	}
+
+	st := status.New(codes.InvalidArgument, validator.Errors(errs).Error())
+	if detailed, err := st.WithDetails(badRequest); err == nil {
+		return detailed
+	}
+	return st
+}
+
+func invalidResponse(method string) error {
+	return status.Errorf(codes.Internal, "%s returned an invalid response", method)
+}
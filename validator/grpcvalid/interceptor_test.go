@@ -0,0 +1,247 @@
+package grpcvalid
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+
+	"github.com/zibilal/structiterator/validator"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	success = "✓"
+	failed  = "✗"
+)
+
+type CreateAccount struct {
+	Name    string   `json:"name" valid:"funcVal:Required"`
+	Email   string   `json:"email" valid:"funcVal:Email"`
+	Contact *Contact `json:"contact"`
+}
+
+type Contact struct {
+	Phone string `json:"phone" valid:"funcVal:Required"`
+}
+
+type Account struct {
+	Id   int    `json:"id" valid:"funcVal:Min,values:1"`
+	Name string `json:"name"`
+}
+
+// jsonCodec lets the test service exchange plain structs instead of generated protobuf messages
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                               { return "json" }
+
+type accountsServer interface{}
+
+// newAccount returns an account with id -1, an invalid response, for the name ghost
+func newAccount(in *CreateAccount) *Account {
+	if in.Name == "ghost" {
+		return &Account{Id: -1, Name: in.Name}
+	}
+	return &Account{Id: len(in.Name), Name: in.Name}
+}
+
+var accountsDesc = grpc.ServiceDesc{
+	ServiceName: "accounts.Accounts",
+	HandlerType: (*accountsServer)(nil),
+	Methods: []grpc.MethodDesc{{
+		MethodName: "Create",
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+			in := new(CreateAccount)
+			if err := dec(in); err != nil {
+				return nil, err
+			}
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				return newAccount(req.(*CreateAccount)), nil
+			}
+			if interceptor == nil {
+				return handler(ctx, in)
+			}
+			return interceptor(ctx, in, &grpc.UnaryServerInfo{Server: srv, FullMethod: "/accounts.Accounts/Create"}, handler)
+		},
+	}},
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "Import",
+		ServerStreams: true,
+		ClientStreams: true,
+		Handler: func(srv interface{}, stream grpc.ServerStream) error {
+			for {
+				in := new(CreateAccount)
+				if err := stream.RecvMsg(in); err == io.EOF {
+					return nil
+				} else if err != nil {
+					return err
+				}
+				if err := stream.SendMsg(newAccount(in)); err != nil {
+					return err
+				}
+			}
+		},
+	}},
+}
+
+func dialAccounts(t *testing.T, interceptor *Interceptor) *grpc.ClientConn {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(
+		grpc.ForceServerCodec(jsonCodec{}),
+		grpc.UnaryInterceptor(interceptor.Unary()),
+		grpc.StreamInterceptor(interceptor.Stream()),
+	)
+	server.RegisterService(&accountsDesc, struct{}{})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultCallOptions(grpc.ForceCodec(jsonCodec{})),
+	)
+	if err != nil {
+		t.Fatalf("%s expected error nil got %s", failed, err.Error())
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// violations returns the status code of err and its field violations as field: description
+func violations(err error) (codes.Code, map[string]string) {
+	st := status.Convert(err)
+	fields := make(map[string]string)
+	for _, detail := range st.Details() {
+		if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+			for _, violation := range badRequest.FieldViolations {
+				fields[violation.Field] = violation.Description
+			}
+		}
+	}
+	return st.Code(), fields
+}
+
+func TestUnary(t *testing.T) {
+	interceptor := NewInterceptor(validator.NewValidStruct(validator.NewValidationMapper()))
+	interceptor.ValidateResponses = true
+	conn := dialAccounts(t, interceptor)
+
+	t.Log("\nTesting valid request")
+	{
+		var account Account
+		err := conn.Invoke(context.Background(), "/accounts.Accounts/Create", &CreateAccount{Name: "Jane", Email: "jane@example.com"}, &account)
+		if err == nil && account.Id == 4 {
+			t.Logf("%s expected account %+v", success, account)
+		} else {
+			t.Errorf("%s expected account got %+v and %v", failed, account, err)
+		}
+	}
+
+	t.Log("\nTesting invalid request fails with field violations")
+	{
+		err := conn.Invoke(context.Background(), "/accounts.Accounts/Create", &CreateAccount{Email: "jane", Contact: &Contact{}}, &Account{})
+		code, fields := violations(err)
+		if code == codes.InvalidArgument && len(fields) == 3 &&
+			fields["name"] == "name is required" && fields["email"] == "email has invalid format value" && fields["contact.phone"] != "" {
+			t.Logf("%s expected violations %v", success, fields)
+		} else {
+			t.Errorf("%s expected violations of name, email and contact.phone got %s %v", failed, code, fields)
+		}
+	}
+
+	t.Log("\nTesting messages follow accept-language metadata")
+	{
+		ctx := metadata.AppendToOutgoingContext(context.Background(), "accept-language", "id-ID,id;q=0.9")
+		err := conn.Invoke(ctx, "/accounts.Accounts/Create", &CreateAccount{Email: "jane@example.com"}, &Account{})
+		if _, fields := violations(err); fields["name"] == "name wajib diisi" {
+			t.Logf("%s expected %s", success, fields["name"])
+		} else {
+			t.Errorf("%s expected name wajib diisi got %v", failed, fields)
+		}
+
+		ctx = metadata.AppendToOutgoingContext(context.Background(), "accept-language", "fr-FR", "accept-language", "id;q=0.8")
+		err = conn.Invoke(ctx, "/accounts.Accounts/Create", &CreateAccount{Email: "jane@example.com"}, &Account{})
+		if _, fields := violations(err); fields["name"] == "name wajib diisi" {
+			t.Logf("%s expected a language without catalog skipped %s", success, fields["name"])
+		} else {
+			t.Errorf("%s expected name wajib diisi got %v", failed, fields)
+		}
+	}
+
+	t.Log("\nTesting invalid response fails with internal error")
+	{
+		err := conn.Invoke(context.Background(), "/accounts.Accounts/Create", &CreateAccount{Name: "ghost"}, &Account{})
+		if code, fields := violations(err); code == codes.Internal && len(fields) == 0 {
+			t.Logf("%s expected %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected internal error got %v", failed, err)
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	conn := dialAccounts(t, DefaultInterceptor)
+
+	t.Log("\nTesting every received message is validated")
+	{
+		stream, err := conn.NewStream(context.Background(), &accountsDesc.Streams[0], "/accounts.Accounts/Import")
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+
+		stream.SendMsg(&CreateAccount{Name: "Jane"})
+		var account Account
+		if err := stream.RecvMsg(&account); err == nil && account.Name == "Jane" {
+			t.Logf("%s expected account %+v", success, account)
+		} else {
+			t.Errorf("%s expected account got %+v and %v", failed, account, err)
+		}
+
+		stream.SendMsg(&CreateAccount{Email: "jane"})
+		err = stream.RecvMsg(&account)
+		if code, fields := violations(err); code == codes.InvalidArgument && len(fields) == 2 {
+			t.Logf("%s expected violations %v", success, fields)
+		} else {
+			t.Errorf("%s expected violations of name and email got %v", failed, err)
+		}
+	}
+
+	t.Log("\nTesting responses are not validated by default")
+	{
+		stream, err := conn.NewStream(context.Background(), &accountsDesc.Streams[0], "/accounts.Accounts/Import")
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+
+		stream.SendMsg(&CreateAccount{Name: "ghost"})
+		var account Account
+		if err := stream.RecvMsg(&account); err == nil && account.Id == -1 {
+			t.Logf("%s expected account %+v", success, account)
+		} else {
+			t.Errorf("%s expected account got %+v and %v", failed, account, err)
+		}
+		stream.CloseSend()
+	}
+}
+
+func TestInvalidArgument(t *testing.T) {
+	t.Log("\nTesting errors without field")
+	{
+		st := InvalidArgument([]error{&validator.FieldError{Field: "name", Rule: "Required", Err: io.EOF}, io.ErrUnexpectedEOF})
+		_, fields := violations(st.Err())
+		if st.Code() == codes.InvalidArgument && len(fields) == 2 && fields[""] == io.ErrUnexpectedEOF.Error() {
+			t.Logf("%s expected violations %v", success, fields)
+		} else {
+			t.Errorf("%s expected 2 violations got %v", failed, fields)
+		}
+	}
+}
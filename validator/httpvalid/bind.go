@@ -0,0 +1,171 @@
+// Package httpvalid binds net/http requests into structs, validates them with validator.ValidStruct
+// and writes failures as RFC 7807 problem details.
+package httpvalid
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/zibilal/structiterator/validator"
+)
+
+// DefaultMaxMemory is the memory used for a multipart body before its files are stored on disk, and the largest body
+const DefaultMaxMemory = 32 << 20
+
+var fileHeaderType = reflect.TypeOf((*multipart.FileHeader)(nil))
+
+// Binder decodes requests into structs and validates them
+type Binder struct {
+	Validator *validator.ValidStruct
+	// MaxMemory is the memory used for a multipart body before its files are stored on disk, and the largest body
+	MaxMemory int64
+	// Locale of error messages, empty means validator.ValidStruct.AcceptLocale of the Accept-Language header
+	Locale string
+	// PathValue returns the path parameter name of r for fields with path tag, nil means r.PathValue of net/http routing
+	PathValue func(r *http.Request, name string) string
+}
+
+// NewBinder creates a Binder validating with v
+func NewBinder(v *validator.ValidStruct) *Binder {
+	return &Binder{Validator: v, MaxMemory: DefaultMaxMemory}
+}
+
+// DefaultBinder is used by Bind
+var DefaultBinder = NewBinder(validator.NewValidStruct(validator.NewValidationMapper()))
+
+// Bind decodes r into dst with DefaultBinder and validates it
+func Bind(r *http.Request, dst interface{}) error {
+	return DefaultBinder.Bind(r, dst)
+}
+
+// Bind fills dst, a pointer to struct, from the query parameters, the body and then the path parameters of r, and validates it.
+// A JSON body is decoded with encoding/json. A form or multipart body, like query parameters, fills the fields with
+// validator.BindValues; a multipart file fills a *multipart.FileHeader or []*multipart.FileHeader field. Path parameters
+// fill the fields by path tag last, so the body can not replace them.
+// The returned error is a *Problem: 415 for an unsupported body, 400 for an undecodable body, 413 for a body
+// larger than MaxMemory and 422 holding the field errors when a value can not be converted or validation fails.
+func (b *Binder) Bind(r *http.Request, dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("bind only accept dst type pointer to struct")
+	}
+
+	maxMemory := b.MaxMemory
+	if maxMemory <= 0 {
+		maxMemory = DefaultMaxMemory
+	}
+
+	var fieldErrs []error
+	fieldErrs = append(fieldErrs, b.bindValues(v.Elem(), r.URL.Query(), nil)...)
+
+	if hasBody(r) {
+		mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		if err != nil {
+			return NewProblem(http.StatusUnsupportedMediaType, "missing or invalid Content-Type")
+		}
+
+		r.Body = http.MaxBytesReader(nil, r.Body, maxMemory)
+		switch {
+		case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+			err = json.NewDecoder(r.Body).Decode(dst)
+			if err != nil && err != io.EOF {
+				return bodyProblem("json", r.Body, err, maxMemory)
+			}
+		case mediaType == "application/x-www-form-urlencoded":
+			if err := r.ParseForm(); err != nil {
+				return bodyProblem("form", r.Body, err, maxMemory)
+			}
+			fieldErrs = append(fieldErrs, b.bindValues(v.Elem(), r.PostForm, nil)...)
+		case mediaType == "multipart/form-data":
+			if err := r.ParseMultipartForm(maxMemory); err != nil {
+				return bodyProblem("multipart", r.Body, err, maxMemory)
+			}
+			fieldErrs = append(fieldErrs, b.bindValues(v.Elem(), r.MultipartForm.Value, r.MultipartForm.File)...)
+		default:
+			return NewProblem(http.StatusUnsupportedMediaType, "unsupported Content-Type "+mediaType)
+		}
+	}
+	fieldErrs = append(fieldErrs, b.bindPath(v.Elem(), r)...)
+
+	if len(fieldErrs) > 0 {
+		return NewValidationProblem(fieldErrs)
+	}
+
+	locale := b.Locale
+	if locale == "" {
+		locale = b.Validator.AcceptLocale(r.Header.Get("Accept-Language"))
+	}
+	if errs := b.Validator.ValidLocaleContext(r.Context(), dst, locale); len(errs) > 0 {
+		return NewValidationProblem(errs)
+	}
+
+	return nil
+}
+
+// bodyProblem is the problem of body failing to decode with err, 413 when it is larger than maxMemory.
+// A parser may report a truncated body as malformed, so the limit is also read back from body, whose error is sticky.
+func bodyProblem(kind string, body io.Reader, err error, maxMemory int64) *Problem {
+	var tooLarge *http.MaxBytesError
+	if _, readErr := body.Read(make([]byte, 1)); errors.As(err, &tooLarge) || errors.As(readErr, &tooLarge) {
+		return NewProblem(http.StatusRequestEntityTooLarge, fmt.Sprintf("%s body is larger than %d bytes", kind, maxMemory))
+	}
+	return NewProblem(http.StatusBadRequest, "invalid "+kind+" body: "+err.Error())
+}
+
+func hasBody(r *http.Request) bool {
+	return r.Body != nil && r.Body != http.NoBody && r.ContentLength != 0
+}
+
+// bindValues sets the fields of v from values with BindValues, and the file fields from files
+func (b *Binder) bindValues(v reflect.Value, values url.Values, files map[string][]*multipart.FileHeader) []error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+		if ft.PkgPath != "" {
+			continue // unexported field
+		}
+
+		headers := files[validator.FormName(ft)]
+		if len(headers) == 0 {
+			continue
+		}
+		switch {
+		case ft.Type == fileHeaderType:
+			v.Field(i).Set(reflect.ValueOf(headers[0]))
+		case ft.Type.Kind() == reflect.Slice && ft.Type.Elem() == fileHeaderType:
+			v.Field(i).Set(reflect.ValueOf(headers))
+		}
+	}
+
+	return b.Validator.BindValues(v.Addr().Interface(), values)
+}
+
+// bindPath sets the fields having a path tag from the path parameters of r
+func (b *Binder) bindPath(v reflect.Value, r *http.Request) []error {
+	pathValue := b.PathValue
+	if pathValue == nil {
+		pathValue = func(r *http.Request, name string) string { return r.PathValue(name) }
+	}
+
+	params := make(map[string]string)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("path"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		if param := pathValue(r, name); param != "" {
+			params[name] = param
+		}
+	}
+
+	return b.Validator.BindPath(v.Addr().Interface(), params)
+}
@@ -0,0 +1,218 @@
+package httpvalid
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/zibilal/structiterator/validator"
+)
+
+const (
+	success = "✓"
+	failed  = "✗"
+)
+
+type SignUp struct {
+	Name    string                `json:"name" valid:"funcVal:Required"`
+	Email   string                `json:"email" valid:"funcVal:Required;funcVal:Email"`
+	Age     int                   `json:"age" valid:"funcVal:Min,values:17"`
+	Tags    []string              `json:"tags"`
+	Ref     string                `form:"ref"`
+	Avatar  *multipart.FileHeader `form:"avatar"`
+	private string
+}
+
+func TestBind(t *testing.T) {
+	t.Log("\nTesting json body and query parameters")
+	{
+		r := httptest.NewRequest(http.MethodPost, "/signup?ref=campaign", strings.NewReader(`{"name": "Jane", "email": "jane@example.com", "age": 20}`))
+		r.Header.Set("Content-Type", "application/json")
+
+		var dst SignUp
+		if err := Bind(r, &dst); err == nil && dst.Name == "Jane" && dst.Ref == "campaign" {
+			t.Logf("%s expected bound value", success)
+		} else {
+			t.Errorf("%s expected bound value got %+v and %v", failed, dst, err)
+		}
+	}
+
+	t.Log("\nTesting form body")
+	{
+		form := url.Values{"name": {"Jane"}, "email": {"jane"}, "age": {"15"}, "tags": {"a", "b"}}
+		r := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(form.Encode()))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		var dst SignUp
+		err := Bind(r, &dst)
+		problem, ok := err.(*Problem)
+		if ok && problem.Status == http.StatusUnprocessableEntity && len(problem.Errors) == 2 &&
+			problem.Errors[0] == (FieldProblem{Field: "email", Rule: "Email", Message: "email has invalid format value"}) &&
+			problem.Errors[1] == (FieldProblem{Field: "age", Rule: "Min", Message: "age must be at least 17"}) &&
+			len(dst.Tags) == 2 {
+			t.Logf("%s expected field problems %v", success, problem.Errors)
+		} else {
+			t.Errorf("%s expected 2 field problems got %#v", failed, err)
+		}
+	}
+
+	t.Log("\nTesting value that can not be converted")
+	{
+		r := httptest.NewRequest(http.MethodGet, "/signup?age=old", nil)
+		err := Bind(r, &SignUp{})
+		problem, ok := err.(*Problem)
+		if ok && len(problem.Errors) == 1 && problem.Errors[0] == (FieldProblem{Field: "age", Rule: "Type", Message: "age has invalid value old"}) {
+			t.Logf("%s expected field problem %v", success, problem.Errors[0])
+		} else {
+			t.Errorf("%s expected field problem of age got %#v", failed, err)
+		}
+	}
+
+	t.Log("\nTesting multipart body")
+	{
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		writer.WriteField("name", "Jane")
+		writer.WriteField("email", "jane@example.com")
+		part, _ := writer.CreateFormFile("avatar", "avatar.png")
+		part.Write([]byte("png"))
+		writer.Close()
+
+		r := httptest.NewRequest(http.MethodPost, "/signup", &body)
+		r.Header.Set("Content-Type", writer.FormDataContentType())
+
+		var dst SignUp
+		if err := Bind(r, &dst); err == nil && dst.Avatar != nil && dst.Avatar.Filename == "avatar.png" {
+			t.Logf("%s expected uploaded file", success)
+		} else {
+			t.Errorf("%s expected uploaded file got %+v and %v", failed, dst, err)
+		}
+	}
+
+	t.Log("\nTesting malformed and unsupported bodies")
+	{
+		r := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(`{"name": `))
+		r.Header.Set("Content-Type", "application/json")
+		if problem, ok := Bind(r, &SignUp{}).(*Problem); ok && problem.Status == http.StatusBadRequest {
+			t.Logf("%s expected status 400", success)
+		} else {
+			t.Errorf("%s expected status 400", failed)
+		}
+
+		r = httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(`<signup/>`))
+		r.Header.Set("Content-Type", "application/xml")
+		if problem, ok := Bind(r, &SignUp{}).(*Problem); ok && problem.Status == http.StatusUnsupportedMediaType {
+			t.Logf("%s expected status 415", success)
+		} else {
+			t.Errorf("%s expected status 415", failed)
+		}
+	}
+
+	t.Log("\nTesting messages follow Accept-Language")
+	{
+		r := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(`{"email": "jane@example.com"}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Accept-Language", "id-ID,id;q=0.9")
+		problem, ok := Bind(r, &SignUp{}).(*Problem)
+		if ok && len(problem.Errors) == 1 && problem.Errors[0].Message == "name wajib diisi" {
+			t.Logf("%s expected %s", success, problem.Errors[0].Message)
+		} else {
+			t.Errorf("%s expected name wajib diisi got %#v", failed, problem)
+		}
+
+		binder := NewBinder(validator.NewValidStruct(validator.NewValidationMapper()))
+		binder.Validator.DefaultLocale = "en"
+		r = httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(`{"name": "Jane", "email": "jane"}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.Header.Set("Accept-Language", "fr-FR,fr;q=0.9")
+		problem, ok = binder.Bind(r, &SignUp{}).(*Problem)
+		if ok && len(problem.Errors) == 1 && problem.Errors[0].Message == "email must be a valid email address" {
+			t.Logf("%s expected DefaultLocale message %s", success, problem.Errors[0].Message)
+		} else {
+			t.Errorf("%s expected email must be a valid email address got %#v", failed, problem)
+		}
+	}
+
+	t.Log("\nTesting json body larger than MaxMemory")
+	{
+		binder := NewBinder(DefaultBinder.Validator)
+		binder.MaxMemory = 16
+		r := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(`{"name": "Jane", "email": "jane@example.com"}`))
+		r.Header.Set("Content-Type", "application/json")
+		if problem, ok := binder.Bind(r, &SignUp{}).(*Problem); ok && problem.Status == http.StatusRequestEntityTooLarge {
+			t.Logf("%s expected status 413", success)
+		} else {
+			t.Errorf("%s expected status 413 got %#v", failed, problem)
+		}
+	}
+
+	t.Log("\nTesting form and multipart bodies larger than MaxMemory")
+	{
+		binder := NewBinder(DefaultBinder.Validator)
+		binder.MaxMemory = 16
+		r := httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader("name=Jane&email=jane@example.com"))
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		if problem, ok := binder.Bind(r, &SignUp{}).(*Problem); ok && problem.Status == http.StatusRequestEntityTooLarge {
+			t.Logf("%s expected status 413 for form body", success)
+		} else {
+			t.Errorf("%s expected status 413 for form body got %#v", failed, problem)
+		}
+
+		body := "--boundary\r\nContent-Disposition: form-data; name=\"name\"\r\n\r\nJane\r\n--boundary--\r\n"
+		r = httptest.NewRequest(http.MethodPost, "/signup", strings.NewReader(body))
+		r.Header.Set("Content-Type", "multipart/form-data; boundary=boundary")
+		if problem, ok := binder.Bind(r, &SignUp{}).(*Problem); ok && problem.Status == http.StatusRequestEntityTooLarge {
+			t.Logf("%s expected status 413 for multipart body", success)
+		} else {
+			t.Errorf("%s expected status 413 for multipart body got %#v", failed, problem)
+		}
+	}
+
+	t.Log("\nTesting path parameters")
+	{
+		type CustomerOrders struct {
+			Customer int      `path:"customer" valid:"funcVal:Min,values:1"`
+			Status   []string `form:"status"`
+		}
+
+		r := httptest.NewRequest(http.MethodGet, "/customers/42/orders?status=paid,shipped", nil)
+		r.SetPathValue("customer", "42")
+
+		var dst CustomerOrders
+		if err := Bind(r, &dst); err == nil && dst.Customer == 42 && len(dst.Status) == 2 {
+			t.Logf("%s expected bound path and query %+v", success, dst)
+		} else {
+			t.Errorf("%s expected bound path and query got %+v and %v", failed, dst, err)
+		}
+
+		type OrderUpdate struct {
+			Id     int    `json:"id" path:"id"`
+			Status string `json:"status"`
+		}
+		r = httptest.NewRequest(http.MethodPut, "/orders/7", strings.NewReader(`{"id": 99, "status": "paid"}`))
+		r.Header.Set("Content-Type", "application/json")
+		r.SetPathValue("id", "7")
+		var update OrderUpdate
+		if err := Bind(r, &update); err == nil && update.Id == 7 && update.Status == "paid" {
+			t.Logf("%s expected path id kept %+v", success, update)
+		} else {
+			t.Errorf("%s expected path id 7 got %+v and %v", failed, update, err)
+		}
+
+		binder := NewBinder(DefaultBinder.Validator)
+		binder.PathValue = func(r *http.Request, name string) string {
+			return strings.Split(r.URL.Path, "/")[2]
+		}
+		err := binder.Bind(httptest.NewRequest(http.MethodGet, "/customers/jane/orders", nil), &dst)
+		if problem, ok := err.(*Problem); ok && len(problem.Errors) == 1 &&
+			problem.Errors[0] == (FieldProblem{Field: "Customer", Rule: "Type", Message: "customer has invalid value jane"}) {
+			t.Logf("%s expected field problem %v", success, problem.Errors[0])
+		} else {
+			t.Errorf("%s expected field problem of customer got %#v", failed, err)
+		}
+	}
+}
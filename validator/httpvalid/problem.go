@@ -0,0 +1,132 @@
+package httpvalid
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"github.com/zibilal/structiterator/validator"
+)
+
+// ProblemContentType is the media type of a problem details body
+const ProblemContentType = "application/problem+json"
+
+// Problem is an RFC 7807 problem details document. Errors lists the field errors of a failed validation.
+type Problem struct {
+	Type     string         `json:"type,omitempty"`
+	Title    string         `json:"title"`
+	Status   int            `json:"status"`
+	Detail   string         `json:"detail,omitempty"`
+	Instance string         `json:"instance,omitempty"`
+	Errors   []FieldProblem `json:"errors,omitempty"`
+}
+
+// FieldProblem is one failed rule, Field is the path of the value and Rule the funcVal.
+// Both are empty for an error not tied to a field.
+type FieldProblem struct {
+	Field   string `json:"field,omitempty"`
+	Rule    string `json:"rule,omitempty"`
+	Message string `json:"message"`
+}
+
+// NewProblem creates a problem of status with detail, titled by the status text
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	}
+}
+
+// NewValidationProblem creates a 422 problem listing errs, the field and rule of a *validator.FieldError are kept
+func NewValidationProblem(errs []error) *Problem {
+	problem := NewProblem(http.StatusUnprocessableEntity, "the request has invalid fields")
+	for _, err := range errs {
+		field := FieldProblem{Message: err.Error()}
+		if fieldErr, ok := err.(*validator.FieldError); ok {
+			field.Field, field.Rule = fieldErr.Field, fieldErr.Rule
+		}
+		problem.Errors = append(problem.Errors, field)
+	}
+	return problem
+}
+
+func (p *Problem) Error() string {
+	if p.Detail == "" {
+		return p.Title
+	}
+	return p.Title + ": " + p.Detail
+}
+
+// WriteProblem writes err as problem details, an error not wrapping a *Problem is written as 500 without its message
+func WriteProblem(w http.ResponseWriter, r *http.Request, err error) {
+	var problem *Problem
+	if !errors.As(err, &problem) {
+		problem = NewProblem(http.StatusInternalServerError, "")
+	}
+	if problem.Instance == "" && r != nil {
+		copied := *problem
+		copied.Instance = r.URL.Path
+		problem = &copied
+	}
+
+	w.Header().Set("Content-Type", ProblemContentType)
+	w.WriteHeader(problem.Status)
+	json.NewEncoder(w).Encode(problem)
+}
+
+// HandlerFunc handles a request whose body is bound and valid, dst is a pointer to a new value of the sample type
+type HandlerFunc func(w http.ResponseWriter, r *http.Request, dst interface{})
+
+// Handler binds every request into a new value of the struct type of sample and calls next with it,
+// a request failing Bind is answered with problem details instead. It returns an error when sample is not a struct.
+func (b *Binder) Handler(sample interface{}, next HandlerFunc) (http.Handler, error) {
+	t, err := structType(sample)
+	if err != nil {
+		return nil, err
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dst := reflect.New(t).Interface()
+		if err := b.Bind(r, dst); err != nil {
+			WriteProblem(w, r, err)
+			return
+		}
+		next(w, r, dst)
+	}), nil
+}
+
+type contextKey struct{}
+
+// Middleware binds every request like Handler and passes the bound value to next in the request context, read it with Value.
+// It returns an error when sample is not a struct.
+func (b *Binder) Middleware(sample interface{}) (func(http.Handler) http.Handler, error) {
+	if _, err := structType(sample); err != nil {
+		return nil, err
+	}
+	return func(next http.Handler) http.Handler {
+		handler, _ := b.Handler(sample, func(w http.ResponseWriter, r *http.Request, dst interface{}) {
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, dst)))
+		})
+		return handler
+	}, nil
+}
+
+// Value returns the value bound by Middleware, a pointer to the sample type, or nil
+func Value(r *http.Request) interface{} {
+	return r.Context().Value(contextKey{})
+}
+
+func structType(sample interface{}) (reflect.Type, error) {
+	t := reflect.TypeOf(sample)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("httpvalid: sample must be a struct, got %T", sample)
+	}
+	return t, nil
+}
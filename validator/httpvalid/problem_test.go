@@ -0,0 +1,124 @@
+package httpvalid
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/zibilal/structiterator/validator"
+)
+
+func TestWriteProblem(t *testing.T) {
+	t.Log("\nTesting error other than problem is written as 500")
+	{
+		w := httptest.NewRecorder()
+		WriteProblem(w, httptest.NewRequest(http.MethodGet, "/orders", nil), errors.New("database is down"))
+
+		var problem Problem
+		json.NewDecoder(w.Body).Decode(&problem)
+		if w.Code == http.StatusInternalServerError && problem.Detail == "" && problem.Instance == "/orders" {
+			t.Logf("%s expected status 500 without the error message", success)
+		} else {
+			t.Errorf("%s expected status 500 without the error message got %d %+v", failed, w.Code, problem)
+		}
+	}
+
+	t.Log("\nTesting wrapped problem keeps its status")
+	{
+		w := httptest.NewRecorder()
+		err := fmt.Errorf("create order: %w", NewProblem(http.StatusConflict, "order exists"))
+		WriteProblem(w, httptest.NewRequest(http.MethodPost, "/orders", nil), err)
+
+		var problem Problem
+		json.NewDecoder(w.Body).Decode(&problem)
+		if w.Code == http.StatusConflict && problem.Detail == "order exists" {
+			t.Logf("%s expected status 409", success)
+		} else {
+			t.Errorf("%s expected status 409 got %d %+v", failed, w.Code, problem)
+		}
+	}
+}
+
+func TestHandler(t *testing.T) {
+	binder := NewBinder(validator.NewValidStruct(validator.NewValidationMapper()))
+	handler, err := binder.Handler(SignUp{}, func(w http.ResponseWriter, r *http.Request, dst interface{}) {
+		w.WriteHeader(http.StatusCreated)
+		w.Write([]byte(dst.(*SignUp).Name))
+	})
+	if err != nil {
+		t.Fatalf("%s expected error nil got %s", failed, err.Error())
+	}
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	t.Log("\nTesting sample other than struct")
+	{
+		for _, sample := range []interface{}{"SignUp", nil, new(int)} {
+			if _, err := binder.Handler(sample, nil); err != nil {
+				t.Logf("%s expected error %s", success, err.Error())
+			} else {
+				t.Errorf("%s expected error for sample %#v", failed, sample)
+			}
+		}
+	}
+
+	t.Log("\nTesting valid request reaches the handler")
+	{
+		resp, err := http.Post(server.URL+"/signup", "application/json", strings.NewReader(`{"name": "Jane", "email": "jane@example.com"}`))
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+		body, _ := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode == http.StatusCreated && string(body) == "Jane" {
+			t.Logf("%s expected status 201", success)
+		} else {
+			t.Errorf("%s expected status 201 got %d %s", failed, resp.StatusCode, body)
+		}
+	}
+
+	t.Log("\nTesting invalid request is answered with problem details")
+	{
+		resp, err := http.Post(server.URL+"/signup", "application/json", strings.NewReader(`{"email": "jane"}`))
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+		defer resp.Body.Close()
+
+		var problem Problem
+		json.NewDecoder(resp.Body).Decode(&problem)
+		if resp.StatusCode == http.StatusUnprocessableEntity && resp.Header.Get("Content-Type") == ProblemContentType &&
+			problem.Instance == "/signup" && problem.Title == "Unprocessable Entity" && len(problem.Errors) == 2 {
+			t.Logf("%s expected problem details %+v", success, problem)
+		} else {
+			t.Errorf("%s expected problem details got %d %+v", failed, resp.StatusCode, problem)
+		}
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Log("\nTesting bound value is passed in context")
+	{
+		middleware, err := DefaultBinder.Middleware(SignUp{})
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+		handler := middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(Value(r).(*SignUp).Email))
+		}))
+
+		r := httptest.NewRequest(http.MethodGet, "/signup?name=Jane&email=jane@example.com", nil)
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, r)
+		if w.Code == http.StatusOK && w.Body.String() == "jane@example.com" {
+			t.Logf("%s expected bound value in context", success)
+		} else {
+			t.Errorf("%s expected bound value in context got %d %s", failed, w.Code, w.Body.String())
+		}
+	}
+}
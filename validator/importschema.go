@@ -0,0 +1,297 @@
+package validator
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// schemaAnnotations are JSON Schema keywords that describe a value without constraining it
+var schemaAnnotations = map[string]bool{
+	"$schema":     true,
+	"$id":         true,
+	"$comment":    true,
+	"title":       true,
+	"description": true,
+	"examples":    true,
+	"default":     true,
+	"deprecated":  true,
+	"readOnly":    true,
+	"writeOnly":   true,
+}
+
+// SchemaRules is a rule set converted from a JSON Schema document. Rules is keyed by value path,
+// like the rules of ValidMap. Unsupported lists the keywords that have no rule, as path: keyword.
+type SchemaRules struct {
+	Rules       map[string]string
+	Unsupported []string
+}
+
+// ImportJSONSchema converts a JSON Schema document of an object into rules. Keywords supported are
+// type, properties, required, enum, pattern, format (email, uri, date and date-time), minimum, maximum,
+// minLength, maxLength, minItems, maxItems and items. Required is converted into funcVal Present, which only rejects
+// an absent value, so a present 0, false or "" is accepted like JSON Schema does. A json null counts as absent.
+func ImportJSONSchema(data []byte) (*SchemaRules, error) {
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("unable to read json schema: %s", err.Error())
+	}
+
+	collected := make(map[string][]string)
+	result := &SchemaRules{Rules: make(map[string]string)}
+	importSchema(root, "", collected, result)
+
+	for path, rules := range collected {
+		result.Rules[path] = strings.Join(rules, ";")
+	}
+	sort.Strings(result.Unsupported)
+
+	return result, nil
+}
+
+// RegisterJSONSchema imports a JSON Schema document and attaches its rules to the struct type of sample,
+// matching properties with json names or field names. Type rules are left out, the Go type of a field
+// already fixes it. The unsupported keywords are returned.
+func (s *ValidStruct) RegisterJSONSchema(sample interface{}, data []byte) ([]string, error) {
+	t, err := sampleStructType(sample)
+	if err != nil {
+		return nil, err
+	}
+
+	imported, err := ImportJSONSchema(data)
+	if err != nil {
+		return nil, err
+	}
+
+	unsupported := imported.Unsupported
+	typeRules := make(map[reflect.Type]map[string]string)
+	var problems []string
+	for path, rules := range imported.Rules {
+		// the Go type of the field already fixes the json type
+		rules = stripTypeRules(rules)
+		if rules == "" {
+			continue
+		}
+		if strings.HasSuffix(path, "*") {
+			unsupported = append(unsupported, path+": rules of slice elements")
+			continue
+		}
+
+		owner, ft, err := fieldByPath(t, path)
+		if err == nil {
+			err = s.checkRules(owner, rules)
+		}
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %s", path, err.Error()))
+			continue
+		}
+
+		if typeRules[owner] == nil {
+			typeRules[owner] = make(map[string]string)
+		}
+		typeRules[owner][ft.Name] = rules
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("json schema does not match %s: %s", t, strings.Join(problems, "; "))
+	}
+
+	for owner, rules := range typeRules {
+		if err := s.RegisterRules(reflect.Zero(owner).Interface(), rules); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.Strings(unsupported)
+	return unsupported, nil
+}
+
+func stripTypeRules(rules string) string {
+	kept := []string{}
+	for _, rule := range SplitTag(rules, ';') {
+		if !strings.HasPrefix(rule, "funcVal:Type,") {
+			kept = append(kept, rule)
+		}
+	}
+	return strings.Join(kept, ";")
+}
+
+func importSchema(schema map[string]interface{}, path string, collected map[string][]string, result *SchemaRules) {
+	keywords := make([]string, 0, len(schema))
+	for keyword := range schema {
+		keywords = append(keywords, keyword)
+	}
+	sort.Strings(keywords)
+
+	unsupported := func(keyword string) {
+		name := path
+		if name == "" {
+			name = "$"
+		}
+		result.Unsupported = append(result.Unsupported, name+": "+keyword)
+	}
+	addRule := func(rule string) {
+		if path == "" {
+			return
+		}
+		collected[path] = append(collected[path], rule)
+	}
+
+	for _, keyword := range keywords {
+		value := schema[keyword]
+
+		switch keyword {
+		case "type":
+			jsonType, ok := schemaType(value)
+			if !ok {
+				unsupported(keyword)
+			} else if path != "" && jsonType != "" {
+				addRule("funcVal:Type,format:" + jsonType)
+			}
+		case "properties":
+			properties, _ := value.(map[string]interface{})
+			names := make([]string, 0, len(properties))
+			for name := range properties {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				if property, ok := properties[name].(map[string]interface{}); ok {
+					importSchema(property, joinSchemaPath(path, name), collected, result)
+				}
+			}
+		case "required":
+			names, _ := value.([]interface{})
+			for _, name := range names {
+				if str, ok := name.(string); ok {
+					childPath := joinSchemaPath(path, str)
+					collected[childPath] = append([]string{"funcVal:Present"}, collected[childPath]...) // a present zero value is accepted
+				}
+			}
+		case "items":
+			items, ok := value.(map[string]interface{})
+			if !ok || path == "" {
+				unsupported(keyword)
+				continue
+			}
+			importSchema(items, path+".*", collected, result)
+		case "enum":
+			values, ok := schemaEnum(value)
+			if !ok || path == "" {
+				unsupported(keyword)
+				continue
+			}
+			addRule("funcVal:AcceptedValues,values:" + QuoteTagValue(values))
+		case "pattern":
+			pattern, _ := value.(string)
+			// a leading @ names a registered pattern
+			if pattern == "" || strings.HasPrefix(pattern, "@") || path == "" {
+				unsupported(keyword)
+				continue
+			}
+			addRule("funcVal:Match,format:" + QuoteTagValue(pattern))
+		case "format":
+			rule, ok := schemaFormats[fmt.Sprintf("%v", value)]
+			if !ok || path == "" {
+				unsupported(keyword)
+				continue
+			}
+			addRule(rule)
+		case "minimum", "maximum", "minLength", "maxLength", "minItems", "maxItems":
+			number, ok := value.(float64)
+			if !ok || path == "" {
+				unsupported(keyword)
+				continue
+			}
+			addRule("funcVal:" + schemaLimits[keyword] + ",values:" + strconv.FormatFloat(number, 'f', -1, 64))
+		default:
+			if !schemaAnnotations[keyword] {
+				unsupported(keyword)
+			}
+		}
+	}
+}
+
+var schemaFormats = map[string]string{
+	"email":     "funcVal:Email",
+	"uri":       "funcVal:Url",
+	"date":      "funcVal:Date,format:yyyy-mm-dd,dateLayout:2006-01-02",
+	"date-time": "funcVal:Date,format:date-time,dateLayout:2006-01-02T15:04:05Z07:00",
+}
+
+var schemaLimits = map[string]string{
+	"minimum":   "Min",
+	"maximum":   "Max",
+	"minLength": "MinLength",
+	"maxLength": "MaxLength",
+	"minItems":  "MinLength",
+	"maxItems":  "MaxLength",
+}
+
+func joinSchemaPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// schemaType reads the type keyword, a type list is accepted when it holds one type besides null
+func schemaType(value interface{}) (string, bool) {
+	switch value.(type) {
+	case string:
+		if value.(string) == "null" {
+			return "", true
+		}
+		return value.(string), true
+	case []interface{}:
+		jsonType := ""
+		for _, t := range value.([]interface{}) {
+			str, ok := t.(string)
+			if !ok {
+				return "", false
+			}
+			if str == "null" {
+				continue
+			}
+			if jsonType != "" {
+				return "", false
+			}
+			jsonType = str
+		}
+		return jsonType, true
+	}
+	return "", false
+}
+
+// schemaEnum joins scalar enum values by |, a value holding tag separators can not be written as rule
+func schemaEnum(value interface{}) (string, bool) {
+	values, ok := value.([]interface{})
+	if !ok || len(values) == 0 {
+		return "", false
+	}
+
+	strs := make([]string, 0, len(values))
+	for _, v := range values {
+		var str string
+		switch v.(type) {
+		case string:
+			str = v.(string)
+		case float64:
+			str = strconv.FormatFloat(v.(float64), 'f', -1, 64)
+		case bool:
+			str = strconv.FormatBool(v.(bool))
+		default:
+			return "", false
+		}
+		if strings.Contains(str, "|") {
+			return "", false
+		}
+		strs = append(strs, str)
+	}
+
+	return strings.Join(strs, "|"), true
+}
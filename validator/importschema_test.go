@@ -0,0 +1,149 @@
+package validator
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const partnerSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"title": "Order",
+	"type": "object",
+	"required": ["order_id", "customer"],
+	"additionalProperties": false,
+	"properties": {
+		"order_id": {"type": "string", "pattern": "^ORD-[0-9]{4}$"},
+		"channel": {"type": "string", "enum": ["web", "app"]},
+		"customer": {
+			"type": "object",
+			"required": ["email"],
+			"properties": {
+				"email": {"type": "string", "format": "email"},
+				"name": {"type": ["string", "null"], "minLength": 3, "maxLength": 20}
+			}
+		},
+		"items": {
+			"type": "array",
+			"minItems": 1,
+			"items": {
+				"type": "object",
+				"properties": {
+					"qty": {"type": "integer", "minimum": 1, "maximum": 10},
+					"note": {"type": "string", "not": {"maxLength": 0}}
+				}
+			}
+		}
+	}
+}`
+
+type PartnerOrder struct {
+	OrderId  string `json:"order_id"`
+	Channel  string `json:"channel"`
+	Customer struct {
+		Email string `json:"email"`
+		Name  string `json:"name"`
+	} `json:"customer"`
+	Items []struct {
+		Qty  int    `json:"qty"`
+		Note string `json:"note"`
+	} `json:"items"`
+}
+
+func TestImportJSONSchema(t *testing.T) {
+	imported, err := ImportJSONSchema([]byte(partnerSchema))
+	if err != nil {
+		t.Fatalf("%s expected error nil got %s", failed, err.Error())
+	}
+
+	t.Log("\nTesting imported rules")
+	{
+		expected := map[string]string{
+			"order_id":       "funcVal:Present;funcVal:Match,format:^ORD-[0-9]{4}$;funcVal:Type,format:string",
+			"channel":        "funcVal:AcceptedValues,values:web|app;funcVal:Type,format:string",
+			"customer":       "funcVal:Present;funcVal:Type,format:object",
+			"customer.email": "funcVal:Present;funcVal:Email;funcVal:Type,format:string",
+			"customer.name":  "funcVal:MaxLength,values:20;funcVal:MinLength,values:3;funcVal:Type,format:string",
+			"items":          "funcVal:MinLength,values:1;funcVal:Type,format:array",
+			"items.*.qty":    "funcVal:Max,values:10;funcVal:Min,values:1;funcVal:Type,format:integer",
+			"items.*.note":   "funcVal:Type,format:string",
+		}
+		for path, rules := range expected {
+			if imported.Rules[path] == rules {
+				t.Logf("%s expected %s: %s", success, path, rules)
+			} else {
+				t.Errorf("%s expected %s: %s got %s", failed, path, rules, imported.Rules[path])
+			}
+		}
+	}
+
+	t.Log("\nTesting unsupported keywords are reported")
+	{
+		expected := "$: additionalProperties,items.*.note: not"
+		if strings.Join(imported.Unsupported, ",") == expected {
+			t.Logf("%s expected %s", success, expected)
+		} else {
+			t.Errorf("%s expected %s got %v", failed, expected, imported.Unsupported)
+		}
+	}
+
+	t.Log("\nTesting imported rules on map payload")
+	{
+		data := map[string]interface{}{}
+		json.Unmarshal([]byte(`{"order_id": "ORD-12", "channel": "pos", "customer": {"name": "Al"}, "items": [{"qty": 11}]}`), &data)
+
+		validtr := NewValidStruct(NewValidationMapper())
+		checkErrorMessages(t, validtr.ValidMap(data, imported.Rules), []string{
+			"wrong value pos, accepted values web|app",
+			"customer.email must be present",
+			"customer.name length must be at least 3",
+			"items.0.qty must be at most 10",
+			"order_id has invalid format value",
+		})
+	}
+
+	t.Log("\nTesting required accepts zero values and type checks them")
+	{
+		zeroSchema := `{"type": "object", "required": ["count", "active"], "properties": {
+			"count": {"type": "integer"}, "name": {"type": "string"},
+			"active": {"type": "boolean", "enum": [true]}, "code": {"type": "string", "pattern": "^[a-z]{2,4}$"}}}`
+		zeroImported, err := ImportJSONSchema([]byte(zeroSchema))
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+
+		validtr := NewValidStruct(NewValidationMapper())
+		data := map[string]interface{}{}
+		json.Unmarshal([]byte(`{"count": 0, "name": 0, "active": true, "code": "abc"}`), &data)
+		checkErrorMessages(t, validtr.ValidMap(data, zeroImported.Rules), []string{"name must be of type string"})
+
+		data = map[string]interface{}{}
+		json.Unmarshal([]byte(`{"name": "", "code": "abcde"}`), &data)
+		checkErrorMessages(t, validtr.ValidMap(data, zeroImported.Rules), []string{
+			"active must be present",
+			"code has invalid format value",
+			"count must be present",
+		})
+	}
+
+	t.Log("\nTesting imported rules on struct")
+	{
+		validtr := NewValidStruct(NewValidationMapper())
+		unsupported, err := validtr.RegisterJSONSchema(PartnerOrder{}, []byte(partnerSchema))
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+		if len(unsupported) == 2 {
+			t.Logf("%s expected 2 unsupported keywords", success)
+		} else {
+			t.Errorf("%s expected 2 unsupported keywords got %v", failed, unsupported)
+		}
+
+		order := PartnerOrder{OrderId: "ORD-1234", Channel: "web"}
+		order.Customer.Email = "buyer@example.com"
+		checkErrorMessages(t, validtr.Valid(order), []string{})
+
+		order.Channel = "pos"
+		checkErrorMessages(t, validtr.Valid(order), []string{"wrong value pos, accepted values web|app"})
+	}
+}
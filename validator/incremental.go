@@ -0,0 +1,189 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+// trackedResult holds the errors of the last validation of a struct by a Revalidator, by field path and name
+type trackedResult struct {
+	locale string
+	fields map[string][]error
+}
+
+// Revalidator revalidates one struct kept in memory, changed one field at a time. It keeps the errors of each field
+// from its last validation, so they are dropped with the Revalidator.
+type Revalidator struct {
+	s     *ValidStruct
+	input interface{}
+	last  *trackedResult
+	lock  sync.Mutex
+}
+
+// NewRevalidator returns a Revalidator of input, a pointer to struct
+func (s *ValidStruct) NewRevalidator(input interface{}) *Revalidator {
+	return &Revalidator{s: s, input: input}
+}
+
+// Revalidate validates the struct of r like Valid. The first call validates every field and keeps the errors
+// of each field. The next calls only rerun the rules of the fields depending on changed, the paths of the fields changed since,
+// and return the updated errors of the whole struct. Without changed every rule is run again.
+func (r *Revalidator) Revalidate(changed ...string) []error {
+	return r.RevalidateLocaleContext(context.Background(), r.s.DefaultLocale, changed...)
+}
+
+// RevalidateLocaleContext revalidates the struct of r like Revalidate, building error messages from the catalog of locale
+// and passing ctx to the Lookup of funcVal Unique and Exists.
+//
+// A field depends on itself and its nested fields, on the fields named by compareKey and on the fields read by an expr rule.
+// A changed path is the key path of a FieldError, like address.zip, the Go names of the fields are accepted as well.
+// Rules reading data outside the struct, like Unique or an expression calling now, only run again when their field changes.
+func (r *Revalidator) RevalidateLocaleContext(ctx context.Context, locale string, changed ...string) []error {
+	s := r.s
+	pv := reflect.ValueOf(r.input)
+	if pv.Kind() != reflect.Ptr || pv.IsNil() || pv.Elem().Kind() != reflect.Struct {
+		return []error{errors.New("revalidate only accept input type pointer to struct")}
+	}
+	v := pv.Elem()
+
+	resultError := s.prepare(v)
+
+	var changedPaths []string
+	for _, name := range changed {
+		path, err := keyPath(v.Type(), strings.Split(name, "."))
+		if err != nil {
+			resultError = append(resultError, err)
+			continue
+		}
+		changedPaths = append(changedPaths, path)
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	last := r.last
+	if len(changed) == 0 || (last != nil && last.locale != locale) {
+		last = nil
+	}
+
+	result := &trackedResult{locale: locale, fields: make(map[string][]error)}
+	walkFields(v, "", s.isTypeValidated, func(parent, fv reflect.Value, ft reflect.StructField, path string) error {
+		key := path + " " + ft.Name // an embedded type has the path of the field holding it
+		errs, found := []error(nil), false
+		if last != nil && !s.affected(parent, ft, path, changedPaths) {
+			errs, found = last.fields[key]
+		}
+		if !found {
+			errs = s.resolveLookups(ctx, s.validField(locale, parent, fv, ft, path))
+		}
+
+		result.fields[key] = errs
+		resultError = append(resultError, errs...)
+		return nil
+	})
+	r.last = result
+
+	if len(resultError) > 0 {
+		return resultError
+	}
+	return nil
+}
+
+// affected reports whether the rules of field ft of parent, at path, depend on one of changed
+func (s *ValidStruct) affected(parent reflect.Value, ft reflect.StructField, path string, changed []string) bool {
+	prefix := strings.TrimSuffix(path, fieldKey(ft))
+	deps := []string{path}
+	if ft.Anonymous && s.isTypeValidated(ft.Type) {
+		// an embedded type adds no key, path is the path of parent and the type depends on its promoted fields
+		prefix, deps = "", nil
+		if path != "" {
+			prefix = path + "."
+		}
+		t := ft.Type
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		for i := 0; t.Kind() == reflect.Struct && i < t.NumField(); i++ {
+			deps = append(deps, prefix+fieldKey(t.Field(i)))
+		}
+	}
+	for _, dep := range s.fieldDeps(parent.Type(), ft) {
+		deps = append(deps, prefix+dep)
+	}
+
+	for _, dep := range deps {
+		for _, c := range changed {
+			if c == "" || dep == c || strings.HasPrefix(c, dep+".") || strings.HasPrefix(dep, c+".") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldDeps returns the key paths, relative to struct type t, of the other fields read by the rules of field ft of t
+func (s *ValidStruct) fieldDeps(t reflect.Type, ft reflect.StructField) []string {
+	dtags := s.fieldRules(t, ft)
+	if dtags == "" {
+		return nil
+	}
+
+	var deps []string
+	addDep := func(name string) {
+		if path, err := keyPath(t, strings.Split(name, ".")); err == nil {
+			deps = append(deps, path)
+		}
+	}
+
+	for _, dtag := range fetchDataTag(dtags, -1, []*dataTag{}) {
+		if dtag.compareKey != "" {
+			addDep(dtag.compareKey)
+		}
+		if dtag.expr != "" {
+			if expr, err := s.compiledExpr(t, dtag.expr); err == nil {
+				for _, field := range expr.Fields() {
+					addDep(field)
+				}
+			}
+		}
+		if dtag.funcVal != "" && IsRuleExpr(dtag.funcVal) {
+			if expr, err := s.parsedRuleExpr(dtag.funcVal); err == nil {
+				for _, funcVal := range expr.FuncVals() {
+					if compareKey := funcVal.Rule().CompareKey; compareKey != "" {
+						addDep(compareKey)
+					}
+				}
+			}
+		}
+	}
+	return deps
+}
+
+// keyPath converts path, field names or json names from struct type t, into the key path of a FieldError.
+// A part through a slice names a field of its elements, a part through a map is kept as it is.
+func keyPath(t reflect.Type, path []string) (string, error) {
+	keys := make([]string, 0, len(path))
+	for i := 0; i < len(path); i++ {
+		for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			t = t.Elem()
+		}
+		if t.Kind() != reflect.Struct || t == timeType {
+			keys = append(keys, path[i:]...)
+			break
+		}
+
+		ft, found := exprField(t, path[i])
+		if !found {
+			return "", fmt.Errorf("field %s is not found in %s", path[i], t)
+		}
+		if !ft.Anonymous {
+			keys = append(keys, fieldKey(ft)) // like walkFields, an embedded struct adds no key, an empty path affects every field
+		}
+		t = ft.Type
+	}
+	return strings.Join(keys, "."), nil
+}
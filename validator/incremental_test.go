@@ -0,0 +1,118 @@
+package validator
+
+import (
+	"errors"
+	"testing"
+)
+
+type TenancyAddress struct {
+	Street string `json:"street" valid:"funcVal:Required"`
+	Zip    string `json:"zip" valid:"funcVal:Counted"`
+}
+
+type Tenancy struct {
+	Tenant      string         `json:"tenant" valid:"funcVal:Counted"`
+	Status      string         `json:"status"`
+	CloseReason string         `json:"close_reason" valid:"funcVal:CondRequired,compareKey:status,compareValue:closed"`
+	Deposit     float64        `json:"deposit"`
+	Rent        float64        `json:"rent" valid:"expr:Deposit <= Rent * 3"`
+	Address     TenancyAddress `json:"address"`
+	Money
+}
+
+func TestRevalidate(t *testing.T) {
+	validtr := NewValidStruct(NewValidationMapper())
+	calls := map[string]int{}
+	validtr.RegisterValidator("Counted", func(value interface{}, key, message string) error {
+		calls[key]++
+		if value == "" {
+			return errors.New(key + " is counted")
+		}
+		return nil
+	})
+
+	tenancy := &Tenancy{Tenant: "jane", Rent: 100, Deposit: 200, Address: TenancyAddress{Street: "Jl. Sudirman 1", Zip: "10220"}, Money: Money{Amount: 100, Currency: "IDR"}}
+	revalidator := validtr.NewRevalidator(tenancy)
+
+	t.Log("\nTesting first Revalidate runs every rule")
+	{
+		if errs := revalidator.Revalidate(); len(errs) == 0 && calls["tenant"] == 1 && calls["zip"] == 1 {
+			t.Logf("%s expected no error", success)
+		} else {
+			t.Errorf("%s expected no error and every rule run got %v and calls %v", failed, errs, calls)
+		}
+	}
+
+	t.Log("\nTesting Revalidate reruns the rules depending on changed fields")
+	{
+		tenancy.Status = "closed"
+		tenancy.Deposit = 500
+		tenancy.Address.Street = ""
+		checkFieldErrors(t, revalidator.Revalidate("status", "Deposit", "address.street"), []string{
+			"close_reason CondRequired: close_reason is required",
+			"rent Expr: rent must satisfy Deposit <= Rent * 3",
+			"address.street Required: street is required",
+		})
+		if calls["tenant"] == 1 && calls["zip"] == 1 {
+			t.Logf("%s expected tenant and zip not checked again", success)
+		} else {
+			t.Errorf("%s expected tenant and zip not checked again got calls %v", failed, calls)
+		}
+
+		tenancy.Tenant = ""
+		tenancy.Amount = -1
+		checkFieldErrors(t, revalidator.Revalidate("tenant", "Amount"), []string{
+			"tenant Counted: tenant is counted",
+			"close_reason CondRequired: close_reason is required",
+			"rent Expr: rent must satisfy Deposit <= Rent * 3",
+			"address.street Required: street is required",
+			" Validate: amount can not be negative",
+		})
+
+		tenancy.Tenant, tenancy.Status, tenancy.Rent, tenancy.Amount = "jane", "active", 200, 100
+		tenancy.Address = TenancyAddress{Street: "Jl. Thamrin 2", Zip: "10230"}
+		if errs := revalidator.Revalidate("tenant", "status", "rent", "Amount", "address"); len(errs) == 0 && calls["tenant"] == 3 && calls["zip"] == 2 {
+			t.Logf("%s expected no error", success)
+		} else {
+			t.Errorf("%s expected no error got %v and calls %v", failed, errs, calls)
+		}
+	}
+
+	t.Log("\nTesting Revalidate keeps the errors of fields not changed")
+	{
+		tenancy.Status, tenancy.Deposit = "closed", 900
+		errs := revalidator.Revalidate("status")
+		checkFieldErrors(t, errs, []string{"close_reason CondRequired: close_reason is required"})
+		checkFieldErrors(t, validtr.Valid(tenancy), []string{
+			"close_reason CondRequired: close_reason is required",
+			"rent Expr: rent must satisfy Deposit <= Rent * 3",
+		})
+
+		// a field missing from changed keeps its stale errors, Revalidate without changed runs every rule
+		checkFieldErrors(t, revalidator.Revalidate(), []string{
+			"close_reason CondRequired: close_reason is required",
+			"rent Expr: rent must satisfy Deposit <= Rent * 3",
+		})
+	}
+
+	t.Log("\nTesting Revalidate of unknown fields and values")
+	{
+		checkFieldErrors(t, revalidator.Revalidate("owner"), []string{
+			"field owner is not found in validator.Tenancy",
+			"close_reason CondRequired: close_reason is required",
+			"rent Expr: rent must satisfy Deposit <= Rent * 3",
+		})
+		checkFieldErrors(t, validtr.NewRevalidator(*tenancy).Revalidate(), []string{"revalidate only accept input type pointer to struct"})
+	}
+
+	t.Log("\nTesting Revalidators of one struct keep their own errors")
+	{
+		other := validtr.NewRevalidator(tenancy)
+		tenancy.Status = "active"
+		checkFieldErrors(t, other.Revalidate("status"), []string{"rent Expr: rent must satisfy Deposit <= Rent * 3"})
+		checkFieldErrors(t, revalidator.Revalidate("deposit"), []string{
+			"close_reason CondRequired: close_reason is required",
+			"rent Expr: rent must satisfy Deposit <= Rent * 3",
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// Lookup answers the rules needing a data source, funcVal Unique and Exists, like funcVal:Unique,format:users.email.
+// Exists reports whether a row of table holds value in column.
+type Lookup interface {
+	Exists(ctx context.Context, table, column string, value interface{}) (bool, error)
+}
+
+// lookupFuncVals are the funcVals run with a Lookup instead of a Validation function
+var lookupFuncVals = map[string]bool{
+	"Unique": true,
+	"Exists": true,
+}
+
+// StructRules returns the funcVals run by ValidStruct itself instead of a Validation function,
+// Unique and Exists with its Lookup and Password with its password policies
+func StructRules() []string {
+	return []string{"Exists", "Password", "Unique"}
+}
+
+// IsStructRule reports whether funcVal is one of StructRules, those rules are not functions of the ValidationMapper
+func IsStructRule(funcVal string) bool {
+	return lookupFuncVals[funcVal] || funcVal == "Password"
+}
+
+// IsLookupRule reports whether funcVal is run with a Lookup
+func IsLookupRule(funcVal string) bool {
+	return lookupFuncVals[funcVal]
+}
+
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// LookupTarget splits format of a lookup rule into table and column, both must be plain identifiers
+func LookupTarget(format string) (table, column string, err error) {
+	splits := strings.Split(format, ".")
+	if len(splits) != 2 || !identifierRegex.MatchString(splits[0]) || !identifierRegex.MatchString(splits[1]) {
+		return "", "", fmt.Errorf("invalid lookup target %q, expected format table.column", format)
+	}
+	return splits[0], splits[1], nil
+}
+
+// lookupCheck is a lookup rule found while a struct is walked. runRules returns it among the errors,
+// so the caller runs the checks of the whole struct together with resolveLookups.
+type lookupCheck struct {
+	path    string
+	funcVal string
+	keyName string
+	format  string
+	message string
+	value   interface{}
+}
+
+func (c *lookupCheck) Error() string {
+	return fmt.Sprintf("%s rule of %s is not resolved", c.funcVal, c.path)
+}
+
+// run returns the error of the check, nil when it passes
+func (c *lookupCheck) run(ctx context.Context, lookup Lookup) error {
+	if lookup == nil {
+		return &FieldError{Field: c.path, Rule: c.funcVal, Err: fmt.Errorf("funcVal %s needs a Lookup, set ValidStruct.Lookup", c.funcVal)}
+	}
+
+	table, column, err := LookupTarget(c.format)
+	if err != nil {
+		return &FieldError{Field: c.path, Rule: c.funcVal, Err: err}
+	}
+
+	exists, err := lookup.Exists(ctx, table, column, c.value)
+	if err != nil {
+		return &FieldError{Field: c.path, Rule: c.funcVal, Err: fmt.Errorf("lookup of %s failed: %s", c.format, err.Error())}
+	}
+	if exists == (c.funcVal == "Exists") {
+		return nil
+	}
+
+	message := c.message
+	if message == "" {
+		if c.funcVal == "Unique" {
+			message = fmt.Sprintf("%s %v is already registered", c.keyName, c.value)
+		} else {
+			message = fmt.Sprintf("%s %v does not exist", c.keyName, c.value)
+		}
+	}
+	return &FieldError{Field: c.path, Rule: c.funcVal, Err: errors.New(message)}
+}
+
+// newLookupCheck returns the check of dtag against fv, or nil for an empty value, which only Required rejects
+func newLookupCheck(dtag *dataTag, fv reflect.Value, keyName, path string) *lookupCheck {
+	if !fv.IsValid() || !fv.CanInterface() || IsEmpty(fv.Interface()) {
+		return nil
+	}
+
+	value := reflect.Indirect(fv)
+	if !value.IsValid() {
+		return nil
+	}
+
+	return &lookupCheck{
+		path:    path,
+		funcVal: dtag.funcVal,
+		keyName: keyName,
+		format:  dtag.format,
+		message: dtag.errorMessage,
+		value:   value.Interface(),
+	}
+}
+
+// resolveLookups runs the lookup checks among errs concurrently and returns errs with each check replaced by its error,
+// or dropped when it passes
+func (s *ValidStruct) resolveLookups(ctx context.Context, errs []error) []error {
+	results := make([]error, len(errs))
+	var wg sync.WaitGroup
+	for i, err := range errs {
+		check, ok := err.(*lookupCheck)
+		if !ok {
+			results[i] = err
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, check *lookupCheck) {
+			defer wg.Done()
+			results[i] = check.run(ctx, s.Lookup)
+		}(i, check)
+	}
+	wg.Wait()
+
+	var resolved []error
+	for _, err := range results {
+		if err != nil {
+			resolved = append(resolved, err)
+		}
+	}
+	return resolved
+}
+
+// MemoryLookup is a Lookup holding its rows in memory, a stand-in for a database in tests.
+// Values are compared by their fmt %v text, so int 1 matches int64 1 and "1". It is safe for concurrent use.
+type MemoryLookup struct {
+	values map[string]map[string]bool
+	sync.RWMutex
+}
+
+// NewMemoryLookup creates an empty MemoryLookup
+func NewMemoryLookup() *MemoryLookup {
+	return &MemoryLookup{values: make(map[string]map[string]bool)}
+}
+
+// Add stores values in column of table
+func (l *MemoryLookup) Add(table, column string, values ...interface{}) {
+	l.Lock()
+	defer l.Unlock()
+
+	key := table + "." + column
+	if l.values[key] == nil {
+		l.values[key] = make(map[string]bool)
+	}
+	for _, value := range values {
+		l.values[key][fmt.Sprintf("%v", value)] = true
+	}
+}
+
+// Remove deletes values from column of table
+func (l *MemoryLookup) Remove(table, column string, values ...interface{}) {
+	l.Lock()
+	defer l.Unlock()
+
+	for _, value := range values {
+		delete(l.values[table+"."+column], fmt.Sprintf("%v", value))
+	}
+}
+
+func (l *MemoryLookup) Exists(ctx context.Context, table, column string, value interface{}) (bool, error) {
+	if err := ctx.Err(); err != nil {
+		return false, err
+	}
+
+	l.RLock()
+	defer l.RUnlock()
+	return l.values[table+"."+column][fmt.Sprintf("%v", value)], nil
+}
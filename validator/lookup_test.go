@@ -0,0 +1,186 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type AgentSignUp struct {
+	Email    string `json:"email" valid:"funcVal:Required;funcVal:Unique,format:users.email"`
+	Nis      string `json:"nis" valid:"funcVal:Exists,format:agents.nis"`
+	Referrer *int   `json:"referrer" valid:"funcVal:Exists,format:agents.id,errorMessage:referrer {value} is unknown"`
+	Team     string `json:"team" valid:"funcVal:Exists,format:teams"`
+}
+
+// failingLookup fails every lookup
+type failingLookup struct{}
+
+func (failingLookup) Exists(ctx context.Context, table, column string, value interface{}) (bool, error) {
+	return false, errors.New("database is down")
+}
+
+// lookupContextKey marks the context expected by contextLookup
+type lookupContextKey struct{}
+
+// contextLookup finds every value, failing the lookups not given the context marked by lookupContextKey
+type contextLookup struct{}
+
+func (contextLookup) Exists(ctx context.Context, table, column string, value interface{}) (bool, error) {
+	if ctx.Value(lookupContextKey{}) == nil {
+		return false, errors.New("context is not passed")
+	}
+	return true, nil
+}
+
+func TestLookupRules(t *testing.T) {
+	lookup := NewMemoryLookup()
+	lookup.Add("users", "email", "jane@example.com")
+	lookup.Add("agents", "nis", "NIS-01")
+	lookup.Add("agents", "id", 7)
+
+	validtr := NewValidStruct(NewValidationMapper())
+	validtr.Lookup = lookup
+
+	t.Log("\nTesting Unique and Exists with a memory lookup")
+	{
+		referrer := 8
+		errs := validtr.Valid(AgentSignUp{Email: "jane@example.com", Nis: "NIS-02", Referrer: &referrer})
+		expected := []string{
+			"email jane@example.com is already registered",
+			"nis NIS-02 does not exist",
+			"referrer 8 is unknown",
+		}
+		matched := len(errs) == len(expected)
+		for i := 0; matched && i < len(errs); i++ {
+			matched = errs[i].Error() == expected[i]
+		}
+		if matched && errs[0].(*FieldError).Rule == "Unique" {
+			t.Logf("%s expected errors in field order %v", success, errs)
+		} else {
+			t.Errorf("%s expected %v got %v", failed, expected, errs)
+		}
+
+		referrer = 7
+		if errs := validtr.Valid(AgentSignUp{Email: "budi@example.com", Nis: "NIS-01", Referrer: &referrer}); len(errs) == 0 {
+			t.Logf("%s expected valid sign up", success)
+		} else {
+			t.Errorf("%s expected valid sign up got %v", failed, errs)
+		}
+	}
+
+	t.Log("\nTesting empty values are not looked up")
+	{
+		errs := validtr.Valid(AgentSignUp{})
+		if len(errs) == 1 && errs[0].Error() == "email is required" {
+			t.Logf("%s expected only error %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected only the required error got %v", failed, errs)
+		}
+	}
+
+	t.Log("\nTesting messages of the catalog of locale")
+	{
+		errs := validtr.ValidLocale(AgentSignUp{Email: "jane@example.com"}, "id")
+		if len(errs) == 1 && errs[0].Error() == "email jane@example.com sudah terdaftar" {
+			t.Logf("%s expected %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected email jane@example.com sudah terdaftar got %v", failed, errs)
+		}
+	}
+
+	t.Log("\nTesting invalid target, failing lookup and missing lookup")
+	{
+		errs := validtr.Valid(AgentSignUp{Email: "budi@example.com", Team: "core"})
+		if len(errs) == 1 && errs[0].Error() == `invalid lookup target "teams", expected format table.column` {
+			t.Logf("%s expected %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected invalid lookup target got %v", failed, errs)
+		}
+
+		failing := NewValidStruct(NewValidationMapper())
+		failing.Lookup = failingLookup{}
+		errs = failing.Valid(AgentSignUp{Email: "budi@example.com"})
+		if len(errs) == 1 && errs[0].Error() == "lookup of users.email failed: database is down" {
+			t.Logf("%s expected %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected failed lookup got %v", failed, errs)
+		}
+
+		errs = NewValidStruct(NewValidationMapper()).Valid(AgentSignUp{Email: "budi@example.com"})
+		if len(errs) == 1 && errs[0].Error() == "funcVal Unique needs a Lookup, set ValidStruct.Lookup" {
+			t.Logf("%s expected %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected missing lookup got %v", failed, errs)
+		}
+	}
+
+	t.Log("\nTesting canceled context")
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		errs := validtr.ValidContext(ctx, AgentSignUp{Email: "budi@example.com"})
+		if len(errs) == 1 && errs[0].Error() == "lookup of users.email failed: context canceled" {
+			t.Logf("%s expected %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected canceled lookup got %v", failed, errs)
+		}
+	}
+
+	t.Log("\nTesting context of ValidMap, ValidCSV, ValidStream and ValidMany")
+	{
+		passing := NewValidStruct(NewValidationMapper())
+		passing.Lookup = contextLookup{}
+		ctx := context.WithValue(context.Background(), lookupContextKey{}, true)
+
+		if errs := passing.ValidMapContext(ctx, map[string]interface{}{"nis": "NIS-01"}, map[string]string{"nis": "funcVal:Exists,format:agents.nis"}); len(errs) == 0 {
+			t.Logf("%s expected the context of ValidMapContext", success)
+		} else {
+			t.Errorf("%s expected the context of ValidMapContext got %v", failed, errs)
+		}
+
+		report, err := passing.ValidCSVContext(ctx, strings.NewReader("email,nis\njane@example.com,NIS-01\n"), AgentSignUp{})
+		if err == nil && len(report.Rows) == 1 && len(report.Rows[0].Errors) == 1 && report.Rows[0].Errors[0].Error() == "row 2, column email: email jane@example.com is already registered" {
+			t.Logf("%s expected the context of ValidCSVContext", success)
+		} else {
+			t.Errorf("%s expected the context of ValidCSVContext got %v and %+v", failed, err, report)
+		}
+
+		var records []StreamRecord
+		err = passing.ValidStreamContext(ctx, strings.NewReader(`{"email": "jane@example.com", "nis": "NIS-01"}`), AgentSignUp{}, func(record StreamRecord) error {
+			records = append(records, record)
+			return nil
+		})
+		if err == nil && len(records) == 1 && len(records[0].Errors) == 1 && records[0].Errors[0].Error() == "email jane@example.com is already registered" {
+			t.Logf("%s expected the context of ValidStreamContext", success)
+		} else {
+			t.Errorf("%s expected the context of ValidStreamContext got %v and %+v", failed, err, records)
+		}
+
+		results, err := passing.ValidMany([]AgentSignUp{{Email: "jane@example.com", Nis: "NIS-01"}}, ManyOptions{Context: ctx})
+		if err == nil && len(results) == 1 && len(results[0].Errors) == 1 && results[0].Errors[0].Error() == "email jane@example.com is already registered" {
+			t.Logf("%s expected the context of ManyOptions", success)
+		} else {
+			t.Errorf("%s expected the context of ManyOptions got %v and %+v", failed, err, results)
+		}
+
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		if _, err := passing.ValidCSVContext(canceled, strings.NewReader("email\njane@example.com\n"), AgentSignUp{}); err == context.Canceled {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected context canceled got %v", failed, err)
+		}
+	}
+
+	t.Log("\nTesting lookups of ValidMap")
+	{
+		errs := validtr.ValidMap(map[string]interface{}{"nis": "NIS-09"}, map[string]string{"nis": "funcVal:Exists,format:agents.nis"})
+		if len(errs) == 1 && errs[0].Error() == "nis NIS-09 does not exist" {
+			t.Logf("%s expected %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected nis NIS-09 does not exist got %v", failed, errs)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// MessageCatalog holds error message templates for one locale.
+// Messages is keyed by funcVal name, a template may use placeholders
+// like {field}, {value}, {format}, {values}, {min}, {max}, {compareKey} and {compareValue}.
+// Fields translates a field key (json name or struct field name) into its display name.
+type MessageCatalog struct {
+	Locale   string            `json:"locale" yaml:"locale"`
+	Messages map[string]string `json:"messages" yaml:"messages"`
+	Fields   map[string]string `json:"fields" yaml:"fields"`
+}
+
+var defaultCatalogs = []MessageCatalog{
+	{
+		Locale: "en",
+		Messages: map[string]string{
+			"Required":             "{field} is required",
+			"NonZero":              "{field} must not be zero or empty",
+			"Present":              "{field} must be present",
+			"CondRequired":         "{field} is required when {compareKey} is {compareValue}",
+			"Email":                "{field} must be a valid email address",
+			"Phone":                "{field} must be a valid phone number",
+			"Url":                  "{field} must be a valid url",
+			"Match":                "{field} has invalid format value",
+			"Date":                 "{field} is expected of format {format}",
+			"AfterDate":            "{field} should be after {compareKey}",
+			"AcceptedValues":       "{field} must be one of {values}",
+			"AcceptedValues.range": "{field} must be between {min} and {max}",
+			"Type":                 "{field} must be of type {format}",
+			"Min":                  "{field} must be at least {values}",
+			"Max":                  "{field} must be at most {values}",
+			"MinLength":            "{field} length must be at least {values}",
+			"MaxLength":            "{field} length must be at most {values}",
+			"Unique":               "{field} {value} is already registered",
+			"Exists":               "{field} {value} does not exist",
+			"Password.minLength":   "{field} must be at least {min} characters",
+			"Password.maxLength":   "{field} must be at most {max} characters",
+			"Password.common":      "{field} is too common",
+			"Password.compare":     "{field} must not contain {compareKey}",
+			"Password.classes":     "{field} must contain {classes} of lowercase, uppercase, digit and symbol characters",
+			"Password.entropy":     "{field} is too easy to guess",
+			"Expression.not":       "{field} must not satisfy {rule}",
+			"Expression.rule":      "{field} must satisfy {rule}",
+			"Expr":                 "{field} must satisfy {expr}",
+			"Expression.and":       "and",
+			"Expression.or":        "or",
+		},
+	},
+	{
+		Locale: "id",
+		Messages: map[string]string{
+			"Required":             "{field} wajib diisi",
+			"NonZero":              "{field} tidak boleh nol atau kosong",
+			"Present":              "{field} harus ada",
+			"CondRequired":         "{field} wajib diisi jika {compareKey} bernilai {compareValue}",
+			"Email":                "{field} harus berupa alamat email yang valid",
+			"Phone":                "{field} harus berupa nomor telepon yang valid",
+			"Url":                  "{field} harus berupa url yang valid",
+			"Match":                "format {field} tidak valid",
+			"Date":                 "{field} harus berformat {format}",
+			"AfterDate":            "{field} harus setelah {compareKey}",
+			"AcceptedValues":       "{field} harus salah satu dari {values}",
+			"AcceptedValues.range": "{field} harus di antara {min} dan {max}",
+			"Type":                 "{field} harus bertipe {format}",
+			"Min":                  "{field} minimal {values}",
+			"Max":                  "{field} maksimal {values}",
+			"MinLength":            "panjang {field} minimal {values}",
+			"MaxLength":            "panjang {field} maksimal {values}",
+			"Unique":               "{field} {value} sudah terdaftar",
+			"Exists":               "{field} {value} tidak ditemukan",
+			"Password.minLength":   "panjang {field} minimal {min} karakter",
+			"Password.maxLength":   "panjang {field} maksimal {max} karakter",
+			"Password.common":      "{field} terlalu umum",
+			"Password.compare":     "{field} tidak boleh memuat {compareKey}",
+			"Password.classes":     "{field} harus memuat {classes} dari huruf kecil, huruf besar, angka dan simbol",
+			"Password.entropy":     "{field} terlalu mudah ditebak",
+			"Expression.not":       "{field} tidak boleh memenuhi {rule}",
+			"Expression.rule":      "{field} harus memenuhi {rule}",
+			"Expr":                 "{field} harus memenuhi {expr}",
+			"Expression.and":       "dan",
+			"Expression.or":        "atau",
+		},
+	},
+}
+
+// LoadCatalog reads a message catalog from a json or yaml file, the format is chosen by file extension
+func LoadCatalog(path string) (*MessageCatalog, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	catalog := new(MessageCatalog)
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(data, catalog)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, catalog)
+	default:
+		return nil, fmt.Errorf("unsupported catalog file %s, expected .json, .yaml or .yml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read catalog %s: %s", path, err.Error())
+	}
+
+	if catalog.Locale == "" {
+		return nil, fmt.Errorf("catalog %s has no locale", path)
+	}
+
+	return catalog, nil
+}
+
+// AddCatalog registers catalog, merging it into a catalog previously registered for the same locale
+func (s *ValidStruct) AddCatalog(catalog *MessageCatalog) {
+	s.catalogLock.Lock()
+	defer s.catalogLock.Unlock()
+
+	if s.catalogs == nil {
+		s.catalogs = make(map[string]*MessageCatalog)
+	}
+
+	locale := strings.ToLower(catalog.Locale)
+	current, found := s.catalogs[locale]
+	if !found {
+		current = &MessageCatalog{
+			Locale:   locale,
+			Messages: make(map[string]string),
+			Fields:   make(map[string]string),
+		}
+		s.catalogs[locale] = current
+	}
+
+	for k, v := range catalog.Messages {
+		current.Messages[k] = v
+	}
+	for k, v := range catalog.Fields {
+		current.Fields[k] = v
+	}
+}
+
+// LoadCatalog reads the catalog file in path and registers it
+func (s *ValidStruct) LoadCatalog(path string) error {
+	catalog, err := LoadCatalog(path)
+	if err != nil {
+		return err
+	}
+	s.AddCatalog(catalog)
+	return nil
+}
+
+// Catalog returns the catalog registered for locale. A regional locale like id-ID
+// falls back to its language catalog when no exact match is registered.
+func (s *ValidStruct) Catalog(locale string) *MessageCatalog {
+	if locale == "" {
+		return nil
+	}
+
+	s.catalogLock.RLock()
+	defer s.catalogLock.RUnlock()
+
+	locale = strings.ToLower(strings.Replace(locale, "_", "-", -1))
+	if catalog, found := s.catalogs[locale]; found {
+		return catalog
+	}
+	if idx := strings.Index(locale, "-"); idx > 0 {
+		if catalog, found := s.catalogs[locale[:idx]]; found {
+			return catalog
+		}
+	}
+
+	return nil
+}
+
+// AcceptLocale returns the locale of error messages for acceptLanguage, the value of an Accept-Language header
+// or accept-language metadata: its first language having a catalog, or DefaultLocale when none has one
+func (s *ValidStruct) AcceptLocale(acceptLanguage string) string {
+	for _, language := range strings.Split(acceptLanguage, ",") {
+		locale := strings.TrimSpace(strings.Split(language, ";")[0])
+		if locale != "" && s.Catalog(locale) != nil {
+			return locale
+		}
+	}
+	return s.DefaultLocale
+}
+
+func (c *MessageCatalog) message(dtag *dataTag) string {
+	if c == nil {
+		return ""
+	}
+	if strings.Contains(dtag.acceptedValues, "<->") {
+		if msg, found := c.Messages[dtag.funcVal+".range"]; found {
+			return msg
+		}
+	}
+	return c.Messages[dtag.funcVal]
+}
+
+func (c *MessageCatalog) fieldName(key string) string {
+	if c != nil {
+		if name, found := c.Fields[key]; found {
+			return name
+		}
+	}
+	return key
+}
+
+// SetErrorMessageMap replaces the error message map, it is safe to call while other goroutines validate.
+// A key is either funcVal, field.funcVal or Type.Field.funcVal, where field is the json name or the struct field name.
+func (s *ValidStruct) SetErrorMessageMap(errorMap map[string]string) {
+	tmp := make(map[string]string, len(errorMap))
+	for k, v := range errorMap {
+		tmp[k] = v
+	}
+
+	s.messageLock.Lock()
+	s.ErrorMessageMap = tmp
+	s.messageLock.Unlock()
+}
+
+// SetErrorMessage sets the message of one key of the error message map
+func (s *ValidStruct) SetErrorMessage(key, message string) {
+	s.messageLock.Lock()
+	defer s.messageLock.Unlock()
+
+	tmp := make(map[string]string, len(s.ErrorMessageMap)+1)
+	for k, v := range s.ErrorMessageMap {
+		tmp[k] = v
+	}
+	tmp[key] = message
+	s.ErrorMessageMap = tmp
+}
+
+// mappedMessage looks up the error message map from the most specific key to the least specific one,
+// those are Type.Field.funcVal, field.funcVal and funcVal
+func (s *ValidStruct) mappedMessage(typeName, fieldName, keyName, funcVal string) string {
+	s.messageLock.RLock()
+	errorMap := s.ErrorMessageMap
+	s.messageLock.RUnlock()
+
+	if len(errorMap) == 0 {
+		return ""
+	}
+
+	keys := []string{
+		keyName + "." + funcVal,
+		fieldName + "." + funcVal,
+		funcVal,
+	}
+	if typeName != "" {
+		keys = append([]string{typeName + "." + fieldName + "." + funcVal}, keys...)
+	}
+	for _, key := range keys {
+		if msg, found := errorMap[key]; found {
+			return msg
+		}
+	}
+
+	return ""
+}
+
+// errorMessage resolves the message used when dtag fails. The lookup order is
+// the errorMessage tag, ErrorMessageMap and then the catalog of locale.
+// An empty result means the validation function builds its own message, a sensitive field gets SensitiveMessage instead.
+func (s *ValidStruct) errorMessage(locale, typeName, fieldName, keyName string, fv reflect.Value, dtag *dataTag) string {
+	return s.errorMessageKey(locale, typeName, fieldName, keyName, fv, dtag, "", nil)
+}
+
+// errorMessageKey resolves the message of dtag like errorMessage, with key instead of the funcVal of dtag in the catalog,
+// like Password.common, and with params added to the placeholders. ErrorMessageMap is looked up by the funcVal of dtag,
+// or by key when dtag has none. An empty key keeps the catalog message of the funcVal.
+func (s *ValidStruct) errorMessageKey(locale, typeName, fieldName, keyName string, fv reflect.Value, dtag *dataTag, key string, params map[string]string) string {
+	catalog := s.Catalog(locale)
+
+	mapKey := dtag.funcVal
+	if mapKey == "" {
+		mapKey = key
+	}
+
+	message := dtag.errorMessage
+	if message == "" {
+		message = s.mappedMessage(typeName, fieldName, keyName, mapKey)
+	}
+	if message == "" && key != "" && catalog != nil {
+		message = catalog.Messages[key]
+	}
+	if message == "" && key == "" {
+		message = catalog.message(dtag)
+	}
+	if message == "" && dtag.sensitive {
+		message = SensitiveMessage
+	}
+	if message == "" {
+		return ""
+	}
+
+	values := s.messageParams(catalog, keyName, fv, dtag)
+	for name, value := range params {
+		values[name] = value
+	}
+	return RenderMessage(message, values)
+}
+
+func (s *ValidStruct) messageParams(catalog *MessageCatalog, keyName string, fv reflect.Value, dtag *dataTag) map[string]string {
+	params := RuleMessageParams(dtag.rule(), catalog.fieldName(keyName), s.DateFormat)
+	params["compareKey"] = catalog.fieldName(dtag.compareKey)
+
+	if dtag.sensitive {
+		params["value"] = Redacted
+	} else if value, ok := messageValue(fv); ok {
+		params["value"] = value
+	}
+
+	return params
+}
+
+// messageValue formats fv for {value}, a pointer is dereferenced and a nil pointer has no value
+func messageValue(fv reflect.Value) (string, bool) {
+	value := reflect.Indirect(fv)
+	if !value.IsValid() || !value.CanInterface() {
+		return "", false
+	}
+	return fmt.Sprintf("%v", value.Interface()), true
+}
+
+// RenderValue replaces {value} in the message of err with value, formatted the way Valid formats {value}.
+// Generated validators pass a message holding {value} to the validation function and render it once the rule failed.
+func RenderValue(err error, value interface{}) error {
+	if err == nil || !strings.Contains(err.Error(), "{value}") {
+		return err
+	}
+	text, ok := messageValue(reflect.ValueOf(value))
+	if !ok {
+		return err
+	}
+	return errors.New(strings.Replace(err.Error(), "{value}", text, -1))
+}
+
+// RuleMessageParams returns the values of the placeholders of an error message template of rule, except {value}.
+// dateFormat is used for {format} of a Date rule without format.
+func RuleMessageParams(rule Rule, keyName, dateFormat string) map[string]string {
+	params := map[string]string{
+		"field":        keyName,
+		"format":       rule.Format,
+		"values":       rule.Values,
+		"compareKey":   rule.CompareKey,
+		"compareValue": rule.CompareValue,
+	}
+
+	if rule.FuncVal == "Date" && rule.Format == "" {
+		params["format"] = dateFormat
+	}
+
+	if strings.Contains(rule.Values, "<->") {
+		bounds := strings.Split(rule.Values, "<->")
+		params["min"], params["max"] = bounds[0], bounds[1]
+	} else if rule.Values != "" {
+		params["values"] = strings.Join(strings.Split(rule.Values, "|"), ", ")
+	}
+
+	return params
+}
+
+// RenderMessage replaces every {name} placeholder in message with its value in params
+func RenderMessage(message string, params map[string]string) string {
+	if !strings.Contains(message, "{") {
+		return message
+	}
+
+	pairs := make([]string, 0, len(params)*2)
+	for k, v := range params {
+		pairs = append(pairs, "{"+k+"}", v)
+	}
+
+	return strings.NewReplacer(pairs...).Replace(message)
+}
@@ -0,0 +1,162 @@
+package validator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type Member struct {
+	Name  string `json:"name" valid:"funcVal:Required"`
+	Email string `json:"email" valid:"funcVal:Email"`
+	Age   int    `json:"age" valid:"funcVal:AcceptedValues,values:17<->60"`
+	Level string `json:"level" valid:"funcVal:AcceptedValues,values:gold|silver,errorMessage:{field} {value} is not a level"`
+}
+
+func TestRenderMessage(t *testing.T) {
+	t.Log("\nTesting render message template")
+	{
+		msg := RenderMessage("{field} must be between {min} and {max}", map[string]string{
+			"field": "age",
+			"min":   "17",
+			"max":   "60",
+		})
+		expected := "age must be between 17 and 60"
+		if msg == expected {
+			t.Logf("%s expected %s", success, expected)
+		} else {
+			t.Errorf("%s expected %s got %s", failed, expected, msg)
+		}
+	}
+}
+
+func TestValidStruct_ValidLocale(t *testing.T) {
+	member := Member{
+		Email: "member@example",
+		Age:   70,
+		Level: "bronze",
+	}
+
+	t.Log("\nTesting valid with locale id")
+	{
+		validtr := NewValidStruct(NewValidationMapper())
+		errs := validtr.ValidLocale(member, "id-ID")
+		expected := []string{
+			"name wajib diisi",
+			"email harus berupa alamat email yang valid",
+			"age harus di antara 17 dan 60",
+			"level bronze is not a level",
+		}
+		checkErrorMessages(t, errs, expected)
+	}
+
+	t.Log("\nTesting valid without locale keeps the default messages")
+	{
+		validtr := NewValidStruct(NewValidationMapper())
+		errs := validtr.Valid(member)
+		expected := []string{
+			"name is required",
+			"email has invalid format value",
+			"70 is outside of range 17 - 60",
+			"level bronze is not a level",
+		}
+		checkErrorMessages(t, errs, expected)
+	}
+}
+
+func TestValidStruct_AcceptLocale(t *testing.T) {
+	validtr := NewValidStruct(NewValidationMapper())
+	validtr.DefaultLocale = "en"
+
+	t.Log("\nTesting the first language having a catalog is picked")
+	{
+		cases := map[string]string{
+			"id-ID,id;q=0.9":      "id-ID",
+			"fr-FR, id;q=0.8, en": "id",
+			"fr-FR,fr;q=0.9":      "en",
+			"":                    "en",
+			"*":                   "en",
+		}
+		for acceptLanguage, expected := range cases {
+			if locale := validtr.AcceptLocale(acceptLanguage); locale == expected {
+				t.Logf("%s expected %q to give %s", success, acceptLanguage, expected)
+			} else {
+				t.Errorf("%s expected %q to give %s got %s", failed, acceptLanguage, expected, locale)
+			}
+		}
+	}
+}
+
+func TestValidStruct_LoadCatalog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "catalog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	yamlFile := filepath.Join(dir, "id.yaml")
+	content := "locale: id\nmessages:\n  Required: \"{field} harus diisi\"\nfields:\n  name: Nama\n"
+	if err := ioutil.WriteFile(yamlFile, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Log("\nTesting load catalog from yaml file")
+	{
+		validtr := NewValidStruct(NewValidationMapper())
+		if err := validtr.LoadCatalog(yamlFile); err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+		errs := validtr.ValidLocale(Member{Email: "member@example.com", Age: 20, Level: "gold"}, "id")
+		checkErrorMessages(t, errs, []string{"Nama harus diisi"})
+	}
+
+	t.Log("\nTesting load catalog with unsupported extension")
+	{
+		validtr := NewValidStruct(NewValidationMapper())
+		if err := validtr.LoadCatalog(filepath.Join(dir, "id.txt")); err != nil {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected error not nil", failed)
+		}
+	}
+}
+
+func checkErrorMessages(t *testing.T, errs []error, expected []string) {
+	if len(errs) != len(expected) {
+		t.Fatalf("%s expected %d errors got %d: %v", failed, len(expected), len(errs), errs)
+	}
+	for i, err := range errs {
+		if err.Error() == expected[i] {
+			t.Logf("%s expected error %s", success, expected[i])
+		} else {
+			t.Errorf("%s expected error %s got %s", failed, expected[i], err.Error())
+		}
+	}
+}
+
+func TestValidStruct_MappedMessage(t *testing.T) {
+	member := Member{Email: "member@example.com", Age: 20, Level: "gold"}
+	person := Person{Email: "person@example.com"}
+
+	t.Log("\nTesting error message map precedence")
+	{
+		validtr := NewValidStructWithMap(NewValidationMapper(), map[string]string{
+			"Required":             "field is required",
+			"Name.Required":        "please tell us the name",
+			"Member.Name.Required": "member name is required",
+		})
+		checkErrorMessages(t, validtr.Valid(member), []string{"member name is required"})
+		checkErrorMessages(t, validtr.Valid(person), []string{"please tell us the name"})
+	}
+
+	t.Log("\nTesting replacing error message map")
+	{
+		validtr := NewValidStruct(NewValidationMapper())
+		validtr.SetErrorMessageMap(map[string]string{"Required": "{field} is mandatory"})
+		checkErrorMessages(t, validtr.Valid(person), []string{"Name is mandatory"})
+
+		validtr.SetErrorMessage("Name.Required", "name is mandatory")
+		checkErrorMessages(t, validtr.Valid(person), []string{"name is mandatory"})
+	}
+}
@@ -0,0 +1,142 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// Modifier transforms a string field value before validation, param is the text after
+// the colon in the mod tag, like n/a in default:n/a
+type Modifier func(value, param string) string
+
+var defaultModifiers = map[string]Modifier{
+	"trim": func(value, param string) string {
+		return strings.TrimSpace(value)
+	},
+	"lower": func(value, param string) string {
+		return strings.ToLower(value)
+	},
+	"upper": func(value, param string) string {
+		return strings.ToUpper(value)
+	},
+	"title": func(value, param string) string {
+		return titleCase(value)
+	},
+	"digits_only": func(value, param string) string {
+		return strings.Map(func(r rune) rune {
+			if unicode.IsDigit(r) {
+				return r
+			}
+			return -1
+		}, value)
+	},
+	"collapse_spaces": func(value, param string) string {
+		return strings.Join(strings.Fields(value), " ")
+	},
+	"default": func(value, param string) string {
+		if strings.TrimSpace(value) == "" {
+			return param
+		}
+		return value
+	},
+}
+
+// RegisterModifier adds or replaces a modifier usable in mod tag
+func (s *ValidStruct) RegisterModifier(name string, f Modifier) error {
+	if name == "" || f == nil {
+		return errors.New("please provide modifier name and function")
+	}
+
+	s.modifierLock.Lock()
+	if s.modifiers == nil {
+		s.modifiers = make(map[string]Modifier)
+	}
+	s.modifiers[name] = f
+	s.modifierLock.Unlock()
+
+	return nil
+}
+
+func (s *ValidStruct) getModifier(name string) (Modifier, error) {
+	s.modifierLock.RLock()
+	f, found := s.modifiers[name]
+	s.modifierLock.RUnlock()
+
+	if !found {
+		return nil, fmt.Errorf("modifier %s is not found", name)
+	}
+
+	return f, nil
+}
+
+// Normalize applies modifiers in mod tag to string fields of input, nested structs included.
+// Modifiers are separated by comma and applied from left to right, like `mod:"trim,lower"`.
+// input must be a pointer to struct, so the fields can be changed.
+func (s *ValidStruct) Normalize(input interface{}) error {
+	v := reflect.ValueOf(input)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return errors.New("normalize only accept input type pointer to struct")
+	}
+
+	return s.normalize(v.Elem())
+}
+
+func (s *ValidStruct) normalize(v reflect.Value) error {
+	return walkStruct(v, func(parent, fv reflect.Value, ft reflect.StructField) error {
+		mtags := ft.Tag.Get("mod")
+		if mtags == "" || !fv.CanSet() {
+			return nil
+		}
+
+		for _, mtag := range strings.Split(mtags, ",") {
+			splits := strings.SplitN(strings.TrimSpace(mtag), ":", 2)
+			param := ""
+			if len(splits) > 1 {
+				param = splits[1]
+			}
+
+			f, err := s.getModifier(splits[0])
+			if err != nil {
+				return fmt.Errorf("%s: %s", ft.Name, err.Error())
+			}
+
+			applyModifier(fv, f, param)
+		}
+
+		return nil
+	})
+}
+
+func applyModifier(fv reflect.Value, f Modifier, param string) {
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(f(fv.String(), param))
+	case reflect.Ptr:
+		if !fv.IsNil() && fv.Elem().Kind() == reflect.String {
+			fv.Elem().SetString(f(fv.Elem().String(), param))
+		}
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() == reflect.String {
+			for i := 0; i < fv.Len(); i++ {
+				fv.Index(i).SetString(f(fv.Index(i).String(), param))
+			}
+		}
+	}
+}
+
+func titleCase(value string) string {
+	runes := []rune(strings.ToLower(value))
+	start := true
+	for i, r := range runes {
+		if unicode.IsSpace(r) {
+			start = true
+		} else if start {
+			runes[i] = unicode.ToUpper(r)
+			start = false
+		}
+	}
+	return string(runes)
+}
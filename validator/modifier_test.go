@@ -0,0 +1,105 @@
+package validator
+
+import (
+	"testing"
+)
+
+type Customer struct {
+	Name    string   `mod:"collapse_spaces,title" valid:"funcVal:Required"`
+	Email   string   `mod:"trim,lower" valid:"funcVal:Required;funcVal:Email"`
+	Phone   string   `mod:"digits_only" valid:"funcVal:Phone"`
+	Segment string   `mod:"trim,default:retail"`
+	Tags    []string `mod:"trim,upper"`
+	Address struct {
+		City string `mod:"trim,upper"`
+	}
+}
+
+func TestValidStruct_Normalize(t *testing.T) {
+	t.Log("\nTesting normalize customer")
+	{
+		customer := Customer{
+			Name:  "  bilal   MUHAMMAD ",
+			Email: " Bilal.Muhammad@Example.COM ",
+			Phone: "0812-1234 5678",
+			Tags:  []string{" vip ", "new"},
+		}
+		customer.Address.City = " jakarta "
+
+		validtr := NewValidStruct(NewValidationMapper())
+		if err := validtr.Normalize(&customer); err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+
+		expected := Customer{
+			Name:    "Bilal Muhammad",
+			Email:   "bilal.muhammad@example.com",
+			Phone:   "081212345678",
+			Segment: "retail",
+			Tags:    []string{"VIP", "NEW"},
+		}
+		expected.Address.City = "JAKARTA"
+
+		if customer.Name == expected.Name && customer.Email == expected.Email && customer.Phone == expected.Phone &&
+			customer.Segment == expected.Segment && customer.Tags[0] == expected.Tags[0] && customer.Tags[1] == expected.Tags[1] &&
+			customer.Address.City == expected.Address.City {
+			t.Logf("%s expected %v", success, expected)
+		} else {
+			t.Errorf("%s expected %v got %v", failed, expected, customer)
+		}
+	}
+
+	t.Log("\nTesting normalize non pointer input")
+	{
+		validtr := NewValidStruct(NewValidationMapper())
+		if err := validtr.Normalize(Customer{}); err != nil {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected error not nil", failed)
+		}
+	}
+}
+
+func TestValidStruct_ValidNormalized(t *testing.T) {
+	t.Log("\nTesting valid pointer input is normalized before validated")
+	{
+		customer := Customer{
+			Name:  "bilal",
+			Email: " Bilal@Example.com ",
+			Phone: "0812 1234",
+		}
+
+		validtr := NewValidStruct(NewValidationMapper())
+		errs := validtr.Valid(&customer)
+		if len(errs) == 0 {
+			t.Logf("%s expected errors nil", success)
+		} else {
+			t.Errorf("%s expected errors nil got %v", failed, errs)
+		}
+	}
+
+	t.Log("\nTesting valid with unknown modifier")
+	{
+		input := struct {
+			Name string `mod:"reverse"`
+		}{}
+
+		validtr := NewValidStruct(NewValidationMapper())
+		checkErrorMessages(t, validtr.Valid(&input), []string{"Name: modifier reverse is not found"})
+
+		validtr.RegisterModifier("reverse", func(value, param string) string {
+			runes := []rune(value)
+			for i, j := 0, len(runes)-1; i < j; i, j = i+1, j-1 {
+				runes[i], runes[j] = runes[j], runes[i]
+			}
+			return string(runes)
+		})
+		input.Name = "abc"
+		checkErrorMessages(t, validtr.Valid(&input), []string{})
+		if input.Name == "cba" {
+			t.Logf("%s expected cba", success)
+		} else {
+			t.Errorf("%s expected cba got %s", failed, input.Name)
+		}
+	}
+}
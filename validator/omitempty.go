@@ -0,0 +1,55 @@
+package validator
+
+import (
+	"reflect"
+	"strings"
+)
+
+// OmitEmptyOption is the valid tag option skipping every rule of a field holding no value, like
+// valid:"omitempty;funcVal:Email". A pointer to a zero value holds a value, so *int 0 and *bool false are checked.
+const OmitEmptyOption = "omitempty"
+
+// IsOmitEmpty reports whether rules, in valid tag syntax, have the omitempty option
+func IsOmitEmpty(rules string) bool {
+	return hasOption(rules, OmitEmptyOption)
+}
+
+// hasOption reports whether rules, in valid tag syntax, have option as a rule of its own
+func hasOption(rules, option string) bool {
+	for _, rule := range SplitTag(rules, ';') {
+		if strings.TrimSpace(rule) == option {
+			return true
+		}
+	}
+	return false
+}
+
+// IsUnset reports whether value holds no value: nil, a nil pointer, interface, map or slice, an empty map or slice,
+// or a value that is empty for IsEmpty. A pointer is never dereferenced, so a pointer to a zero value is set.
+// A driver.Valuer is checked by its underlying value, so a null sql.NullString is unset.
+func IsUnset(value interface{}) bool {
+	value = Underlying(value)
+	if value == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Func, reflect.Chan:
+		return v.IsNil()
+	case reflect.Map, reflect.Slice:
+		return v.Len() == 0
+	}
+	return IsEmpty(value)
+}
+
+// isUnsetValue is IsUnset of fv, an invalid value is unset
+func isUnsetValue(fv reflect.Value) bool {
+	if !fv.IsValid() {
+		return true
+	}
+	if !fv.CanInterface() {
+		return false
+	}
+	return IsUnset(fv.Interface())
+}
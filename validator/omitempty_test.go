@@ -0,0 +1,108 @@
+package validator
+
+import (
+	"database/sql"
+	"testing"
+)
+
+type ProfileUpdate struct {
+	Nickname  string         `json:"nickname" valid:"omitempty;funcVal:MinLength,values:3"`
+	Age       *int           `json:"age" valid:"omitempty;funcVal:Min,values:17"`
+	Subscribe *bool          `json:"subscribe" valid:"funcVal:Required"`
+	Credit    *int           `json:"credit" valid:"funcVal:NonZero"`
+	Refund    Money          `json:"refund" valid:"omitempty"`
+	Tags      []string       `json:"tags" valid:"omitempty;funcVal:MaxLength,values:2"`
+	Referrer  sql.NullString `json:"referrer" valid:"omitempty;funcVal:MinLength,values:4"`
+}
+
+func TestOmitEmpty(t *testing.T) {
+	validtr := NewValidStruct(NewValidationMapper())
+	subscribe, credit := false, 10
+
+	t.Log("\nTesting omitempty skips the rules of empty fields")
+	{
+		update := ProfileUpdate{Subscribe: &subscribe, Credit: &credit, Tags: []string{}, Referrer: sql.NullString{}}
+		if errs := validtr.Valid(update); len(errs) == 0 {
+			t.Logf("%s expected no error, *bool false is required and set", success)
+		} else {
+			t.Errorf("%s expected no error got %v", failed, errs)
+		}
+	}
+
+	t.Log("\nTesting omitempty checks fields holding a value, a pointer to zero included")
+	{
+		zero := 0
+		update := ProfileUpdate{
+			Nickname:  "al",
+			Age:       &zero,
+			Subscribe: &subscribe,
+			Credit:    &credit,
+			Refund:    Money{Amount: -5, Currency: "IDR"},
+			Tags:      []string{"a", "b", "c"},
+			Referrer:  sql.NullString{String: "ad", Valid: true},
+		}
+		checkFieldErrors(t, validtr.Valid(update), []string{
+			"nickname MinLength: nickname length must be at least 3",
+			"age Min: age must be at least 17",
+			"refund Validate: amount can not be negative",
+			"tags MaxLength: tags length must be at most 2",
+			"referrer MinLength: referrer length must be at least 4",
+		})
+	}
+
+	t.Log("\nTesting Required and NonZero on pointers")
+	{
+		zero := 0
+		checkFieldErrors(t, validtr.Valid(ProfileUpdate{Credit: &zero}), []string{
+			"subscribe Required: subscribe is required",
+			"credit NonZero: credit must not be zero or empty",
+		})
+		checkFieldErrors(t, validtr.ValidLocale(ProfileUpdate{Subscribe: &subscribe}, "id"), []string{
+			"credit NonZero: credit tidak boleh nol atau kosong",
+		})
+	}
+
+	t.Log("\nTesting omitempty of ValidMap and RuleBuilder")
+	{
+		rules := map[string]string{"coupon": "omitempty;funcVal:MinLength,values:6"}
+		if errs := validtr.ValidMap(map[string]interface{}{"coupon": ""}, rules); len(errs) == 0 {
+			t.Logf("%s expected empty coupon skipped", success)
+		} else {
+			t.Errorf("%s expected no error got %v", failed, errs)
+		}
+		if errs := validtr.ValidMap(map[string]interface{}{"coupon": "ab"}, rules); len(errs) == 1 {
+			t.Logf("%s expected error %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected one error got %v", failed, errs)
+		}
+
+		builder := NewRuleBuilder()
+		builder.Field("Nickname").OmitEmpty().NonZero()
+		if built := builder.Rules()["Nickname"]; built == "omitempty;funcVal:NonZero" && ParseRules(built)[0].OmitEmpty {
+			t.Logf("%s expected rules %s", success, built)
+		} else {
+			t.Errorf("%s expected omitempty;funcVal:NonZero got %s", failed, built)
+		}
+	}
+
+	t.Log("\nTesting IsUnset")
+	{
+		var nilInt *int
+		zero := 0
+		cases := []struct {
+			value    interface{}
+			expected bool
+		}{
+			{nil, true}, {nilInt, true}, {&zero, false}, {0, true}, {false, true}, {"  ", true},
+			{[]int{}, true}, {map[string]int{}, true}, {[]int{0}, false},
+			{sql.NullInt64{}, true}, {sql.NullInt64{Valid: true}, true}, {sql.NullInt64{Int64: 1, Valid: true}, false},
+		}
+		for _, c := range cases {
+			if IsUnset(c.value) == c.expected {
+				t.Logf("%s expected IsUnset(%#v) %t", success, c.value, c.expected)
+			} else {
+				t.Errorf("%s expected IsUnset(%#v) %t", failed, c.value, c.expected)
+			}
+		}
+	}
+}
@@ -0,0 +1,260 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PasswordPolicy is the policy checked by funcVal Password, like funcVal:Password,format:admin,compareKey:email.
+// format names a policy registered with RegisterPasswordPolicy, empty for DefaultPasswordPolicy, and compareKey
+// a field the password must not contain, like the email or the user name.
+type PasswordPolicy struct {
+	MinLength int
+	// MaxLength of zero means no maximum
+	MaxLength int
+	// MinClasses is how many of the character classes lowercase, uppercase, digit and symbol the password must contain
+	MinClasses int
+	// MinEntropy is the least estimated entropy in bits, zero disables the check
+	MinEntropy float64
+	// RejectCommon rejects the built in list of common passwords and Common, ignoring case and trailing digits and symbols
+	RejectCommon bool
+	Common       []string
+}
+
+// DefaultPasswordPolicy is used by funcVal Password without format, unless a policy named default is registered
+var DefaultPasswordPolicy = PasswordPolicy{
+	MinLength:    8,
+	MaxLength:    256,
+	MinClasses:   3,
+	MinEntropy:   40,
+	RejectCommon: true,
+}
+
+// RegisterPasswordPolicy names policy, rules use it with format:name, like funcVal:Password,format:admin
+func (s *ValidStruct) RegisterPasswordPolicy(name string, policy PasswordPolicy) error {
+	if name == "" || strings.ContainsAny(name, ",;:") {
+		return fmt.Errorf("invalid password policy name %q", name)
+	}
+	if policy.MaxLength > 0 && policy.MinLength > policy.MaxLength {
+		return fmt.Errorf("password policy %s has MinLength %d above MaxLength %d", name, policy.MinLength, policy.MaxLength)
+	}
+	if policy.MinClasses > 4 {
+		return fmt.Errorf("password policy %s has MinClasses %d, there are 4 character classes", name, policy.MinClasses)
+	}
+
+	s.passwordLock.Lock()
+	if s.passwordPolicies == nil {
+		s.passwordPolicies = make(map[string]PasswordPolicy)
+	}
+	s.passwordPolicies[name] = policy
+	s.passwordLock.Unlock()
+
+	return nil
+}
+
+func (s *ValidStruct) passwordPolicy(name string) (PasswordPolicy, error) {
+	s.passwordLock.RLock()
+	policy, found := s.passwordPolicies[name]
+	s.passwordLock.RUnlock()
+
+	if found {
+		return policy, nil
+	}
+	if name == "" {
+		return s.passwordPolicy("default")
+	}
+	if name == "default" {
+		return DefaultPasswordPolicy, nil
+	}
+	return PasswordPolicy{}, fmt.Errorf("password policy %s is not registered", name)
+}
+
+// checkPassword runs funcVal Password of dtag against fv. The password is never written in the error message.
+func (s *ValidStruct) checkPassword(locale string, parent, fv reflect.Value, typeName, fieldName, keyName string, dtag *dataTag) error {
+	value := reflect.Indirect(fv)
+	if !value.IsValid() || IsEmpty(value.Interface()) {
+		return nil // only Required rejects an empty value
+	}
+	if value.Kind() != reflect.String {
+		return fmt.Errorf("%s must be a string", keyName)
+	}
+	password := value.String()
+
+	policy, err := s.passwordPolicy(dtag.format)
+	if err != nil {
+		return err
+	}
+
+	reason := ""
+	length := utf8.RuneCountInString(password)
+	switch {
+	case length < policy.MinLength:
+		reason = "minLength"
+	case policy.MaxLength > 0 && length > policy.MaxLength:
+		reason = "maxLength"
+	case policy.RejectCommon && isCommonPassword(password, policy.Common):
+		reason = "common"
+	case dtag.compareKey != "" && containsCompared(password, parent, dtag.compareKey):
+		reason = "compare"
+	case characterClasses(password) < policy.MinClasses:
+		reason = "classes"
+	case policy.MinEntropy > 0 && PasswordEntropy(password) < policy.MinEntropy:
+		reason = "entropy"
+	default:
+		return nil
+	}
+
+	message := s.errorMessageKey(locale, typeName, fieldName, keyName, fv, dtag, "Password."+reason, map[string]string{
+		"min":     strconv.Itoa(policy.MinLength),
+		"max":     strconv.Itoa(policy.MaxLength),
+		"classes": strconv.Itoa(policy.MinClasses),
+		"value":   Redacted,
+	})
+	if message != "" {
+		return errors.New(message)
+	}
+
+	switch reason {
+	case "minLength":
+		return fmt.Errorf("%s must be at least %d characters", keyName, policy.MinLength)
+	case "maxLength":
+		return fmt.Errorf("%s must be at most %d characters", keyName, policy.MaxLength)
+	case "common":
+		return fmt.Errorf("%s is too common", keyName)
+	case "compare":
+		return fmt.Errorf("%s must not contain %s", keyName, dtag.compareKey)
+	case "classes":
+		return fmt.Errorf("%s must contain %d of lowercase, uppercase, digit and symbol characters", keyName, policy.MinClasses)
+	}
+	return fmt.Errorf("%s is too easy to guess", keyName)
+}
+
+// containsCompared reports whether password contains the value of field key of parent, or the part before @ of an email.
+// Values shorter than 3 characters are ignored.
+func containsCompared(password string, parent reflect.Value, key string) bool {
+	var compared reflect.Value
+	if parent.Kind() == reflect.Map {
+		compared = mapEntry(parent, key)
+	} else if parent.Kind() == reflect.Struct {
+		if ft, found := fieldByKey(parent.Type(), key); found {
+			compared = parent.FieldByIndex(ft.Index)
+		}
+	}
+	compared = reflect.Indirect(compared)
+	if !compared.IsValid() || !compared.CanInterface() {
+		return false
+	}
+
+	text := strings.ToLower(strings.TrimSpace(fmt.Sprintf("%v", compared.Interface())))
+	password = strings.ToLower(password)
+	for _, part := range []string{text, strings.Split(text, "@")[0]} {
+		if len(part) >= 3 && strings.Contains(password, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// characterClasses counts the classes among lowercase, uppercase, digit and symbol found in password
+func characterClasses(password string) int {
+	var lower, upper, digit, symbol int
+	for _, r := range password {
+		switch {
+		case unicode.IsLower(r):
+			lower = 1
+		case unicode.IsUpper(r):
+			upper = 1
+		case unicode.IsDigit(r):
+			digit = 1
+		default:
+			symbol = 1
+		}
+	}
+	return lower + upper + digit + symbol
+}
+
+// PasswordEntropy estimates the entropy of password in bits, from the size of the character classes it uses.
+// A character repeating the one before it adds nothing, so aaaaaaaa scores like a.
+func PasswordEntropy(password string) float64 {
+	var pool float64
+	var lower, upper, digit, symbol, other bool
+	length := 0
+	var previous rune = -1
+	for _, r := range password {
+		switch {
+		case r > unicode.MaxASCII:
+			other = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsDigit(r):
+			digit = true
+		default:
+			symbol = true
+		}
+		if r != previous {
+			length++
+		}
+		previous = r
+	}
+
+	for _, class := range []struct {
+		used bool
+		size float64
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.used {
+			pool += class.size
+		}
+	}
+	if pool == 0 {
+		return 0
+	}
+	return float64(length) * math.Log2(pool)
+}
+
+// isCommonPassword reports whether password, lowercased and without trailing digits and symbols, is a common password
+func isCommonPassword(password string, extra []string) bool {
+	lowered := strings.ToLower(password)
+	trimmed := strings.TrimRightFunc(lowered, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	for _, candidate := range []string{lowered, trimmed} {
+		if commonPasswords[candidate] {
+			return true
+		}
+		for _, common := range extra {
+			if strings.ToLower(common) == candidate {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+var commonPasswords = func() map[string]bool {
+	list := []string{
+		"123456", "password", "12345678", "qwerty", "123456789", "12345", "1234", "111111", "1234567", "dragon",
+		"123123", "baseball", "abc123", "football", "monkey", "letmein", "696969", "shadow", "master", "666666",
+		"qwertyuiop", "123321", "mustang", "1234567890", "michael", "654321", "superman", "1qaz2wsx", "7777777", "121212",
+		"000000", "qazwsx", "123qwe", "killer", "trustno1", "jordan", "jennifer", "zxcvbnm", "asdfgh", "hunter",
+		"buster", "soccer", "harley", "batman", "andrew", "tigger", "sunshine", "iloveyou", "charlie", "robert",
+		"thomas", "hockey", "ranger", "daniel", "starwars", "112233", "george", "computer", "michelle", "jessica",
+		"pepper", "zxcvbn", "555555", "11111111", "131313", "freedom", "777777", "pass", "maggie", "159753",
+		"aaaaaa", "ginger", "princess", "joshua", "cheese", "amanda", "summer", "love", "ashley", "nicole",
+		"chelsea", "matthew", "access", "yankees", "987654321", "dallas", "austin", "thunder", "taylor", "matrix",
+		"admin", "welcome", "passw0rd", "p@ssw0rd", "qwerty123", "administrator", "changeme", "secret", "default", "login",
+	}
+	common := make(map[string]bool, len(list))
+	for _, password := range list {
+		common[password] = true
+	}
+	return common
+}()
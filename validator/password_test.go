@@ -0,0 +1,125 @@
+package validator
+
+import (
+	"testing"
+)
+
+type Credentials struct {
+	Email    string `json:"email" valid:"funcVal:Required;funcVal:Email"`
+	Password string `json:"password" valid:"funcVal:Required;funcVal:Password,compareKey:email"`
+}
+
+type AdminCredentials struct {
+	Login    string `json:"login"`
+	Password string `json:"password" valid:"funcVal:Password,format:admin,compareKey:login"`
+}
+
+func TestPassword(t *testing.T) {
+	validtr := NewValidStruct(NewValidationMapper())
+
+	t.Log("\nTesting default password policy")
+	{
+		cases := []struct {
+			password string
+			expected string
+		}{
+			{"Tr0ub4dor&3", ""},
+			{"short1A", "password must be at least 8 characters"},
+			{"Password1!", "password is too common"},
+			{"Jane2024!x", "password must not contain email"},
+			{"alllowercase", "password must contain 3 of lowercase, uppercase, digit and symbol characters"},
+			{"aaaaaaaA1", "password is too easy to guess"},
+		}
+		for _, c := range cases {
+			errs := validtr.Valid(Credentials{Email: "jane@example.com", Password: c.password})
+			if (c.expected == "" && len(errs) == 0) || (len(errs) == 1 && errs[0].Error() == c.expected && errs[0].(*FieldError).Rule == "Password") {
+				t.Logf("%s expected %q for %s", success, c.expected, c.password)
+			} else {
+				t.Errorf("%s expected %q for %s got %v", failed, c.expected, c.password, errs)
+			}
+		}
+	}
+
+	t.Log("\nTesting messages of the catalog of locale")
+	{
+		errs := validtr.ValidLocale(Credentials{Email: "jane@example.com", Password: "short1A"}, "id")
+		if len(errs) == 1 && errs[0].Error() == "panjang password minimal 8 karakter" {
+			t.Logf("%s expected %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected panjang password minimal 8 karakter got %v", failed, errs)
+		}
+	}
+
+	t.Log("\nTesting registered policy")
+	{
+		err := validtr.RegisterPasswordPolicy("admin", PasswordPolicy{MinLength: 16, MinClasses: 4, RejectCommon: true, Common: []string{"company"}})
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+
+		cases := []struct {
+			password string
+			expected string
+		}{
+			{"Tr0ub4dor&3", "password must be at least 16 characters"},
+			{"Company2024!!!!!", "password is too common"},
+			{"rootAdmin#123456", "password must not contain login"},
+			{"correct-Horse-battery-9", ""},
+		}
+		for _, c := range cases {
+			errs := validtr.Valid(AdminCredentials{Login: "rootadmin", Password: c.password})
+			if (c.expected == "" && len(errs) == 0) || (len(errs) == 1 && errs[0].Error() == c.expected) {
+				t.Logf("%s expected %q for %s", success, c.expected, c.password)
+			} else {
+				t.Errorf("%s expected %q for %s got %v", failed, c.expected, c.password, errs)
+			}
+		}
+
+		if err := validtr.RegisterPasswordPolicy("broken", PasswordPolicy{MinLength: 10, MaxLength: 8}); err != nil {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected error of MinLength above MaxLength", failed)
+		}
+	}
+
+	t.Log("\nTesting unregistered policy and errorMessage")
+	{
+		errs := NewValidStruct(NewValidationMapper()).Valid(AdminCredentials{Password: "Tr0ub4dor&3"})
+		if len(errs) == 1 && errs[0].Error() == "password policy admin is not registered" {
+			t.Logf("%s expected %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected unregistered policy got %v", failed, errs)
+		}
+
+		errs = validtr.ValidMap(map[string]interface{}{"pin": "1234"}, map[string]string{"pin": "funcVal:Password,errorMessage:{field} {value} is weak"})
+		if len(errs) == 1 && errs[0].Error() == "pin [REDACTED] is weak" {
+			t.Logf("%s expected %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected pin [REDACTED] is weak got %v", failed, errs)
+		}
+	}
+
+	t.Log("\nTesting ErrorMessageMap of Password and Expr")
+	{
+		mapped := NewValidStructWithMap(NewValidationMapper(), map[string]string{
+			"Password":       "{field} is not accepted",
+			"pin.Expr":       "{field} must be 6 digits",
+			"Expression.and": "as well as",
+		})
+		rules := map[string]string{"password": "funcVal:Password", "pin": "expr:len(pin) == 6", "code": "funcVal:MinLength:3&Email"}
+		checkErrorMessages(t, mapped.ValidMapLocale(map[string]interface{}{"password": "short1A", "pin": "123", "code": "x"}, rules, "en"), []string{
+			"code length must be at least 3 as well as code must be a valid email address",
+			"password is not accepted",
+			"pin must be 6 digits",
+		})
+	}
+
+	t.Log("\nTesting entropy estimate")
+	{
+		if low, high := PasswordEntropy("aaaaaaaa"), PasswordEntropy("Tr0ub4dor&3"); low < 5 && high > 70 {
+			t.Logf("%s expected entropy %.1f and %.1f", success, low, high)
+		} else {
+			t.Errorf("%s expected low and high entropy got %.1f and %.1f", failed, low, high)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package validator
+
+import (
+	"container/list"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const DefaultPatternCacheSize = 256
+
+// DefaultMaxPatternLength is the longest regular expression Match compiles, and DefaultMaxMatchInputLength the longest
+// value, in bytes, Match runs a regular expression on, until SetPatternLimits changes them
+const (
+	DefaultMaxPatternLength    = 1024
+	DefaultMaxMatchInputLength = 64 * 1024
+)
+
+// maxPatternLength and maxMatchInputLength are read by Match concurrently, always access them atomically
+var (
+	maxPatternLength    int64 = DefaultMaxPatternLength
+	maxMatchInputLength int64 = DefaultMaxMatchInputLength
+)
+
+// SetPatternLimits sets the longest regular expression Match compiles and the longest value, in bytes,
+// Match runs a regular expression on. It is safe to call while values are validated.
+func SetPatternLimits(maxPattern, maxInput int) {
+	atomic.StoreInt64(&maxPatternLength, int64(maxPattern))
+	atomic.StoreInt64(&maxMatchInputLength, int64(maxInput))
+}
+
+// PatternLimits returns the limits set with SetPatternLimits
+func PatternLimits() (maxPattern, maxInput int) {
+	return int(atomic.LoadInt64(&maxPatternLength)), int(atomic.LoadInt64(&maxMatchInputLength))
+}
+
+// patterns holds the regular expressions compiled by Match, the least recently used one is dropped when full
+var patterns = newPatternCache(DefaultPatternCacheSize)
+
+type patternCache struct {
+	size    int
+	entries map[string]*list.Element
+	order   *list.List
+	sync.Mutex
+}
+
+type patternEntry struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+func newPatternCache(size int) *patternCache {
+	return &patternCache{
+		size:    size,
+		entries: make(map[string]*list.Element),
+		order:   list.New(),
+	}
+}
+
+// SetPatternCacheSize sets how many compiled regular expressions are kept, a size below 1 disables the cache
+func SetPatternCacheSize(size int) {
+	patterns.Lock()
+	defer patterns.Unlock()
+
+	patterns.size = size
+	patterns.evict()
+}
+
+// compilePattern returns the compiled pattern, compiling it only when it is not in the cache
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if maxPattern, _ := PatternLimits(); len(pattern) > maxPattern {
+		return nil, fmt.Errorf("regular expression is longer than %d bytes", maxPattern)
+	}
+
+	if re, found := patterns.get(pattern); found {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regular expression %s: %s", pattern, err.Error())
+	}
+	patterns.add(pattern, re)
+
+	return re, nil
+}
+
+func (c *patternCache) get(pattern string) (*regexp.Regexp, bool) {
+	c.Lock()
+	defer c.Unlock()
+
+	elem, found := c.entries[pattern]
+	if !found {
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return elem.Value.(*patternEntry).re, true
+}
+
+func (c *patternCache) add(pattern string, re *regexp.Regexp) {
+	c.Lock()
+	defer c.Unlock()
+
+	if c.size < 1 {
+		return
+	}
+	if elem, found := c.entries[pattern]; found {
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[pattern] = c.order.PushFront(&patternEntry{pattern: pattern, re: re})
+	c.evict()
+}
+
+// evict drops the least recently used patterns over size, the lock must be held
+func (c *patternCache) evict() {
+	for c.order.Len() > 0 && c.order.Len() > c.size {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*patternEntry).pattern)
+	}
+}
+
+func (c *patternCache) len() int {
+	c.Lock()
+	defer c.Unlock()
+	return c.order.Len()
+}
+
+// RegisterPattern names a regular expression, rules use it with format:@name, like funcVal:Match,format:@voucher.
+// The pattern is compiled when registered, an invalid or too long pattern is rejected.
+func (s *ValidStruct) RegisterPattern(name, pattern string) error {
+	if name == "" || strings.ContainsAny(name, ",;:") {
+		return fmt.Errorf("invalid pattern name %q", name)
+	}
+	if _, err := compilePattern(pattern); err != nil {
+		return err
+	}
+
+	s.patternLock.Lock()
+	if s.namedPatterns == nil {
+		s.namedPatterns = make(map[string]string)
+	}
+	s.namedPatterns[name] = pattern
+	s.patternLock.Unlock()
+
+	return nil
+}
+
+// resolvePattern returns the regular expression of format, replacing @name with the pattern registered as name
+func (s *ValidStruct) resolvePattern(format string) (string, error) {
+	if !strings.HasPrefix(format, "@") {
+		return format, nil
+	}
+
+	s.patternLock.RLock()
+	pattern, found := s.namedPatterns[format[1:]]
+	s.patternLock.RUnlock()
+
+	if !found {
+		return "", fmt.Errorf("pattern %s is not registered", format[1:])
+	}
+	return pattern, nil
+}
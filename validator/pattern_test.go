@@ -0,0 +1,99 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+)
+
+type PromoVoucher struct {
+	Code string `json:"code" valid:"funcVal:Required;funcVal:Match,format:@voucher"`
+}
+
+func TestPatternCache(t *testing.T) {
+	defer SetPatternCacheSize(DefaultPatternCacheSize)
+
+	t.Log("\nTesting compiled patterns are reused")
+	{
+		SetPatternCacheSize(2)
+		first, _ := compilePattern("^a+$")
+		second, _ := compilePattern("^a+$")
+		if first == second {
+			t.Logf("%s expected the cached expression", success)
+		} else {
+			t.Errorf("%s expected the cached expression", failed)
+		}
+	}
+
+	t.Log("\nTesting the least recently used pattern is dropped")
+	{
+		compilePattern("^b+$")
+		compilePattern("^a+$")
+		compilePattern("^c+$")
+		_, foundA := patterns.get("^a+$")
+		_, foundB := patterns.get("^b+$")
+		if patterns.len() == 2 && foundA && !foundB {
+			t.Logf("%s expected ^b+$ dropped", success)
+		} else {
+			t.Errorf("%s expected ^b+$ dropped got len %d, ^a+$ %v, ^b+$ %v", failed, patterns.len(), foundA, foundB)
+		}
+	}
+
+	t.Log("\nTesting length limits")
+	{
+		validation := Validation{}
+		err := validation.Match("abc", "code", strings.Repeat("a", DefaultMaxPatternLength+1), "")
+		if err != nil && strings.Contains(err.Error(), "regular expression is longer than") {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected pattern length error got %v", failed, err)
+		}
+
+		err = validation.Match(strings.Repeat("a", DefaultMaxMatchInputLength+1), "code", "^a+$", "")
+		if err != nil && err.Error() == "code is longer than 65536 bytes" {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected input length error got %v", failed, err)
+		}
+
+		SetPatternLimits(DefaultMaxPatternLength, 4)
+		err = validation.Match("aaaaa", "code", "^a+$", "")
+		SetPatternLimits(DefaultMaxPatternLength, DefaultMaxMatchInputLength)
+		if err != nil && err.Error() == "code is longer than 4 bytes" {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected input length error got %v", failed, err)
+		}
+	}
+}
+
+func TestValidStruct_RegisterPattern(t *testing.T) {
+	validtr := NewValidStruct(NewValidationMapper())
+
+	t.Log("\nTesting invalid pattern is rejected")
+	{
+		if err := validtr.RegisterPattern("broken", "^([0-9]$"); err != nil {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected error invalid regular expression", failed)
+		}
+	}
+
+	t.Log("\nTesting named pattern in rules")
+	{
+		if err := validtr.RegisterPattern("voucher", `^VC-[0-9]{6}$`); err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+
+		checkErrorMessages(t, validtr.Valid(PromoVoucher{Code: "VC-123456"}), []string{})
+		checkErrorMessages(t, validtr.Valid(PromoVoucher{Code: "VC-12"}), []string{"code has invalid format value"})
+	}
+
+	t.Log("\nTesting named pattern in map rules")
+	{
+		errs := validtr.ValidMap(map[string]interface{}{"code": "X"}, map[string]string{"code": "funcVal:Match,format:@voucher"})
+		checkErrorMessages(t, errs, []string{"code has invalid format value"})
+
+		errs = validtr.ValidMap(map[string]interface{}{"promo": "NEWYEAR"}, map[string]string{"promo": "funcVal:Match,format:@promo"})
+		checkErrorMessages(t, errs, []string{"pattern promo is not registered"})
+	}
+}
@@ -0,0 +1,443 @@
+package validator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+// RuleConfig is a rule set document. Types is keyed by the name of a type added with RegisterType,
+// each type maps a field path to rules in valid tag syntax. A field path separates nested struct fields by dot,
+// the rules of a nested field are attached to the nested struct type, wherever it is used. A nested field
+// configured with different rules through two paths, like User.Address.City and Company.Address.City, is rejected.
+// When Override is true the rules replace the valid tag of the fields, otherwise they are run after it.
+//
+//	override: false
+//	types:
+//	  User:
+//	    Email: "funcVal:Required;funcVal:Email"
+//	    Address.City: "funcVal:Required"
+type RuleConfig struct {
+	Override bool                         `json:"override" yaml:"override"`
+	Types    map[string]map[string]string `json:"types" yaml:"types"`
+}
+
+// RegisterType makes the struct type of samples known by name to rule configurations, the type name (User),
+// the package qualified name (models.User) and the import path qualified name (example.com/app/models.User) are accepted.
+// A name already registered for another type is kept by that type, RegisterType then returns an error naming the conflict
+// and the other names of the sample are still registered.
+func (s *ValidStruct) RegisterType(samples ...interface{}) error {
+	s.ruleLock.Lock()
+	defer s.ruleLock.Unlock()
+
+	if s.namedTypes == nil {
+		s.namedTypes = make(map[string]reflect.Type)
+	}
+
+	var conflicts []string
+	for _, sample := range samples {
+		t := reflect.TypeOf(sample)
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t == nil || t.Kind() != reflect.Struct {
+			return errors.New("register type only accept sample type struct")
+		}
+
+		for _, name := range []string{t.Name(), t.String(), t.PkgPath() + "." + t.Name()} {
+			if registered, found := s.namedTypes[name]; found && registered != t {
+				conflicts = append(conflicts, fmt.Sprintf("type name %s of %s.%s is registered for %s.%s",
+					name, t.PkgPath(), t.Name(), registered.PkgPath(), registered.Name()))
+				continue
+			}
+			s.namedTypes[name] = t
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return errors.New(strings.Join(conflicts, "; "))
+	}
+	return nil
+}
+
+// ParseRuleConfig reads a rule configuration in json or yaml format
+func ParseRuleConfig(data []byte, format string) (*RuleConfig, error) {
+	config := new(RuleConfig)
+
+	var err error
+	switch strings.ToLower(strings.TrimPrefix(format, ".")) {
+	case "json":
+		err = json.Unmarshal(data, config)
+	case "yaml", "yml":
+		err = yaml.Unmarshal(data, config)
+	default:
+		return nil, fmt.Errorf("unsupported rule config format %s, expected json or yaml", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to read rule config: %s", err.Error())
+	}
+
+	return config, nil
+}
+
+// LoadRules reads the rule configuration file in path, the format is chosen by file extension.
+// The configuration is checked first, on any error the rules loaded before stay in use.
+// Loaded rules replace the rules of the previous LoadRules call, rules added with RegisterRules are kept.
+func (s *ValidStruct) LoadRules(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	config, err := ParseRuleConfig(data, filepath.Ext(path))
+	if err != nil {
+		return fmt.Errorf("%s: %s", path, err.Error())
+	}
+
+	if err := s.ApplyRuleConfig(config); err != nil {
+		return fmt.Errorf("%s: %s", path, err.Error())
+	}
+
+	return nil
+}
+
+// ApplyRuleConfig checks config and replaces the rules of the previously applied configuration
+func (s *ValidStruct) ApplyRuleConfig(config *RuleConfig) error {
+	configRules, err := s.compileRuleConfig(config)
+	if err != nil {
+		return err
+	}
+
+	s.ruleLock.Lock()
+	s.configRules = configRules
+	s.ruleLock.Unlock()
+
+	return nil
+}
+
+// WatchRules loads the rule configuration in path and reloads it every time the file changes,
+// checking its modification time every interval. A failed reload keeps the rules in use and is
+// reported to onError when it is not nil. Call the returned function to stop watching.
+func (s *ValidStruct) WatchRules(path string, interval time.Duration, onError func(error)) (func(), error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.LoadRules(path); err != nil {
+		return nil, err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		modTime := info.ModTime()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				info, err := os.Stat(path)
+				if err != nil {
+					if onError != nil {
+						onError(err)
+					}
+					continue
+				}
+				if info.ModTime().Equal(modTime) {
+					continue
+				}
+				modTime = info.ModTime()
+				if err := s.LoadRules(path); err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+
+	return func() { close(done) }, nil
+}
+
+func (s *ValidStruct) compileRuleConfig(config *RuleConfig) (map[reflect.Type]map[string]registeredRule, error) {
+	configRules := make(map[reflect.Type]map[string]registeredRule)
+	origins := make(map[reflect.Type]map[string]string) // the type and path configuring a field
+
+	var problems []string
+	for typeName, fields := range config.Types {
+		s.ruleLock.RLock()
+		t, found := s.namedTypes[typeName]
+		s.ruleLock.RUnlock()
+
+		if !found {
+			problems = append(problems, fmt.Sprintf("type %s is not registered", typeName))
+			continue
+		}
+
+		for path, rules := range fields {
+			owner, ft, err := fieldByPath(t, path)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s.%s: %s", typeName, path, err.Error()))
+				continue
+			}
+
+			if err := s.checkRules(owner, rules); err != nil {
+				problems = append(problems, fmt.Sprintf("%s.%s: %s", typeName, path, err.Error()))
+				continue
+			}
+
+			origin := typeName + "." + path
+			if configured, found := configRules[owner][ft.Name]; found && configured.rules != rules {
+				first, second := origins[owner][ft.Name], origin
+				if first > second {
+					first, second = second, first // map order is random, keep the message stable
+				}
+				problems = append(problems, fmt.Sprintf("%s and %s configure field %s of %s with different rules", first, second, ft.Name, owner))
+				continue
+			}
+
+			if configRules[owner] == nil {
+				configRules[owner] = make(map[string]registeredRule)
+				origins[owner] = make(map[string]string)
+			}
+			configRules[owner][ft.Name] = registeredRule{rules: rules, override: config.Override}
+			origins[owner][ft.Name] = origin
+		}
+	}
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, fmt.Errorf("invalid rule config: %s", strings.Join(problems, "; "))
+	}
+
+	return configRules, nil
+}
+
+// fieldByPath finds the field at path in struct type t, it also returns the struct type holding the field.
+// A * part selects the element of the slice before it, like items.*.sku.
+func fieldByPath(t reflect.Type, path string) (reflect.Type, reflect.StructField, error) {
+	parts := strings.Split(path, ".")
+	for i, part := range parts {
+		if part == "*" {
+			continue // the element type is resolved with the slice field
+		}
+
+		ft, found := fieldByKey(t, part)
+		if !found {
+			return nil, reflect.StructField{}, fmt.Errorf("field %s is not found in %s", part, t)
+		}
+		if i == len(parts)-1 {
+			return t, ft, nil
+		}
+		if parts[i+1] == "*" && i+1 == len(parts)-1 {
+			return nil, reflect.StructField{}, fmt.Errorf("rules of slice %s elements need a struct field", part)
+		}
+
+		next := ft.Type
+		for next.Kind() == reflect.Ptr || next.Kind() == reflect.Slice || next.Kind() == reflect.Array {
+			next = next.Elem()
+		}
+		if !isNestedStruct(next) {
+			return nil, reflect.StructField{}, fmt.Errorf("field %s is not a struct", part)
+		}
+		t = next
+	}
+
+	return nil, reflect.StructField{}, fmt.Errorf("empty field path")
+}
+
+// checkRules reports rules of a field of struct type t that can not run, those are unknown funcVal,
+// rules failing CheckRule and compareKey not found in t
+func (s *ValidStruct) checkRules(t reflect.Type, rules string) error {
+	if rules == "" {
+		return nil
+	}
+
+	dataTags := []*dataTag{}
+	dataTags = fetchDataTag(rules, -1, dataTags)
+
+	for _, dtag := range dataTags {
+		if dtag.expr != "" && dtag.funcVal == "" {
+			expr, err := ParseExpr(dtag.expr)
+			if err == nil && t != nil {
+				err = expr.Check(t)
+			}
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if dtag.funcVal == "" && (dtag.sensitive || dtag.omitEmpty) {
+			continue // an option, not a rule
+		}
+		if dtag.funcVal == "" {
+			return fmt.Errorf("rule %s has no funcVal", rules)
+		}
+		funcVals := []string{dtag.funcVal}
+		if IsRuleExpr(dtag.funcVal) {
+			expr, err := ParseRuleExpr(dtag.funcVal)
+			if err != nil {
+				return err
+			}
+			funcVals = funcVals[:0]
+			for _, funcVal := range expr.FuncVals() {
+				funcVals = append(funcVals, funcVal.FuncVal)
+			}
+		}
+		for _, funcVal := range funcVals {
+			if _, err := s.mapper.GetFunc(funcVal); err != nil && !IsStructRule(funcVal) {
+				return err
+			}
+		}
+
+		if dtag.funcVal == "Match" {
+			pattern, err := s.resolvePattern(dtag.format)
+			if err != nil {
+				return err
+			}
+			dtag.format = pattern
+		}
+
+		if err := CheckRule(dtag.rule()); err != nil {
+			return err
+		}
+
+		if dtag.compareKey != "" && t != nil {
+			if _, found := fieldByKey(t, dtag.compareKey); !found {
+				return fmt.Errorf("compareKey %s is not found in %s", dtag.compareKey, t)
+			}
+		}
+	}
+
+	return nil
+}
+
+// jsonTypes are the formats accepted by funcVal Type
+var jsonTypes = map[string]bool{
+	"string":  true,
+	"integer": true,
+	"number":  true,
+	"boolean": true,
+	"object":  true,
+	"array":   true,
+}
+
+// CheckRule reports a rule of a default funcVal that can not run as written, those are invalid regular expression,
+// invalid date layout, unknown json type, missing or malformed values and a lookup target other than table.column.
+// A rule expression is parsed and each of its funcVals is checked with its argument, the expression of an expr rule is parsed.
+// It does not check the funcVal or a named pattern in format:@name is registered.
+func CheckRule(rule Rule) error {
+	if rule.Expr != "" {
+		if rule.FuncVal != "" {
+			return fmt.Errorf("rule has both funcVal %s and expr", rule.FuncVal)
+		}
+		_, err := ParseExpr(rule.Expr)
+		return err
+	}
+
+	if IsRuleExpr(rule.FuncVal) {
+		expr, err := ParseRuleExpr(rule.FuncVal)
+		if err != nil {
+			return err
+		}
+		for _, funcVal := range expr.FuncVals() {
+			if err := checkRuleExprFuncVal(funcVal.FuncVal); err != nil {
+				return err
+			}
+			if err := CheckRule(funcVal.Rule()); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	switch rule.FuncVal {
+	case "Match":
+		if rule.Format == "" {
+			return errors.New("Match needs format")
+		}
+		if strings.HasPrefix(rule.Format, "@") {
+			break // named pattern, checked when registered
+		}
+		if maxPattern, _ := PatternLimits(); len(rule.Format) > maxPattern {
+			return fmt.Errorf("regular expression is longer than %d bytes", maxPattern)
+		}
+		if _, err := regexp.Compile(rule.Format); err != nil {
+			return fmt.Errorf("invalid regular expression %s: %s", rule.Format, err.Error())
+		}
+	case "Date":
+		if (rule.Format == "") != (rule.DateLayout == "") {
+			return errors.New("Date needs both format and dateLayout, or none of them")
+		}
+		if rule.DateLayout != "" && !isDateLayout(rule.DateLayout) {
+			return fmt.Errorf("invalid date layout %s, expected a layout of reference time 2006-01-02 15:04:05", rule.DateLayout)
+		}
+	case "Unique", "Exists":
+		if _, _, err := LookupTarget(rule.Format); err != nil {
+			return err
+		}
+	case "AcceptedValues":
+		if rule.Values == "" {
+			return errors.New("AcceptedValues needs values")
+		}
+		if strings.Contains(rule.Values, "<->") {
+			bounds := strings.Split(rule.Values, "<->")
+			if len(bounds) != 2 {
+				return fmt.Errorf("invalid range %s, expected min<->max", rule.Values)
+			}
+			for _, bound := range bounds {
+				if _, err := strconv.ParseFloat(bound, 64); err != nil {
+					return fmt.Errorf("invalid range %s, expected min<->max", rule.Values)
+				}
+			}
+		}
+	case "Min", "Max":
+		if _, err := strconv.ParseFloat(rule.Values, 64); err != nil {
+			return fmt.Errorf("%s needs a number value", rule.FuncVal)
+		}
+	case "MinLength", "MaxLength":
+		if _, err := strconv.Atoi(rule.Values); err != nil {
+			return fmt.Errorf("%s needs an integer value", rule.FuncVal)
+		}
+	case "Type":
+		if rule.Format == "" {
+			return errors.New("Type needs format")
+		}
+		if !jsonTypes[rule.Format] {
+			return fmt.Errorf("unknown json type %s, expected string, integer, number, boolean, object or array", rule.Format)
+		}
+	case "CondRequired":
+		if rule.CompareKey == "" || rule.CompareValue == "" {
+			return errors.New("CondRequired needs compareKey and compareValue")
+		}
+	case "AfterDate":
+		if rule.CompareKey == "" {
+			return errors.New("AfterDate needs compareKey")
+		}
+	}
+
+	return nil
+}
+
+// isDateLayout reports whether layout holds a time element and parses the time it formats.
+// The sample differs from the reference time in every element, so a layout without element formats to itself.
+func isDateLayout(layout string) bool {
+	sample := time.Date(2019, time.November, 23, 17, 38, 49, 0, time.UTC)
+	formatted := sample.Format(layout)
+	if formatted == layout {
+		return false
+	}
+	_, err := time.Parse(layout, formatted)
+	return err == nil
+}
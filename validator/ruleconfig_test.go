@@ -0,0 +1,193 @@
+package validator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type Product struct {
+	Code     string `json:"code"`
+	Name     string `json:"name" valid:"funcVal:Required"`
+	Category string `json:"category"`
+	Seller   struct {
+		Phone string `json:"phone"`
+	} `json:"seller"`
+}
+
+const productRules = `
+types:
+  Product:
+    code: "funcVal:Required;funcVal:Match,format:^P[0-9]+$"
+    seller.phone: "funcVal:Required"
+`
+
+func TestValidStruct_LoadRules(t *testing.T) {
+	dir, err := ioutil.TempDir("", "rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	validtr := NewValidStruct(NewValidationMapper())
+	validtr.RegisterType(Product{})
+
+	product := Product{Code: "X1", Name: "Voucher"}
+
+	t.Log("\nTesting load rules from yaml")
+	{
+		path := filepath.Join(dir, "rules.yaml")
+		ioutil.WriteFile(path, []byte(productRules), 0644)
+
+		if err := validtr.LoadRules(path); err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+		checkErrorMessages(t, validtr.Valid(product), []string{"code has invalid format value", "phone is required"})
+	}
+
+	t.Log("\nTesting invalid rules are rejected and previous rules stay")
+	{
+		path := filepath.Join(dir, "rules.json")
+		ioutil.WriteFile(path, []byte(`{"types": {
+			"Product": {"code": "funcVal:Match,format:^P[0-9+$", "category": "funcVal:Mandatory", "name": "funcVal:CondRequired,compareKey:type,compareValue:x"},
+			"Order": {"id": "funcVal:Required"}
+		}}`), 0644)
+
+		err := validtr.LoadRules(path)
+		if err == nil {
+			t.Fatalf("%s expected error not nil", failed)
+		}
+		for _, problem := range []string{"type Order is not registered", "invalid regular expression", "func name Mandatory is not found", "compareKey type is not found"} {
+			if strings.Contains(err.Error(), problem) {
+				t.Logf("%s expected error contains %s", success, problem)
+			} else {
+				t.Errorf("%s expected error contains %s got %s", failed, problem, err.Error())
+			}
+		}
+		checkErrorMessages(t, validtr.Valid(product), []string{"code has invalid format value", "phone is required"})
+	}
+
+	t.Log("\nTesting watch rules reloads changed file")
+	{
+		path := filepath.Join(dir, "watch.json")
+		ioutil.WriteFile(path, []byte(`{"override": true, "types": {"Product": {"name": ""}}}`), 0644)
+
+		stop, err := validtr.WatchRules(path, 10*time.Millisecond, nil)
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+		defer stop()
+		checkErrorMessages(t, validtr.Valid(Product{}), []string{})
+
+		ioutil.WriteFile(path, []byte(`{"types": {"Product": {"category": "funcVal:Required"}}}`), 0644)
+		later := time.Now().Add(time.Second)
+		os.Chtimes(path, later, later)
+
+		deadline := time.Now().Add(2 * time.Second)
+		for len(validtr.Valid(Product{})) != 2 && time.Now().Before(deadline) {
+			time.Sleep(10 * time.Millisecond)
+		}
+		checkErrorMessages(t, validtr.Valid(Product{}), []string{"name is required", "category is required"})
+	}
+}
+
+func TestCheckRule(t *testing.T) {
+	t.Log("\nTesting rules that can not run are reported")
+	{
+		invalid := map[string]string{
+			"funcVal:Match,format:^([0-9]$":                     "invalid regular expression",
+			"funcVal:Date,format:yyyy-mm-dd":                    "Date needs both format and dateLayout",
+			"funcVal:Date,format:yyyy-mm-dd,dateLayout:YYYY-MM": "invalid date layout YYYY-MM",
+			"funcVal:AcceptedValues,values:1<->x":               "invalid range 1<->x",
+			"funcVal:Type,format:text":                          "unknown json type text",
+			"funcVal:MinLength,values:1.5":                      "MinLength needs an integer value",
+		}
+		for rules, expected := range invalid {
+			err := CheckRule(ParseRules(rules)[0])
+			if err != nil && strings.Contains(err.Error(), expected) {
+				t.Logf("%s expected %s: %s", success, rules, err.Error())
+			} else {
+				t.Errorf("%s expected %s: %s got %v", failed, rules, expected, err)
+			}
+		}
+	}
+
+	t.Log("\nTesting runnable rules pass")
+	{
+		for _, rules := range []string{
+			"funcVal:Date",
+			"funcVal:Date,format:yyyy-mm-dd,dateLayout:2006-01-02",
+			"funcVal:AcceptedValues,values:17<->99",
+			"funcVal:Type,format:integer",
+			"funcVal:Custom,format:anything",
+		} {
+			if err := CheckRule(ParseRules(rules)[0]); err == nil {
+				t.Logf("%s expected %s to pass", success, rules)
+			} else {
+				t.Errorf("%s expected %s to pass got %s", failed, rules, err.Error())
+			}
+		}
+	}
+}
+
+type Depot struct {
+	City string `json:"city"`
+}
+
+type Warehouse struct {
+	Depot Depot `json:"depot"`
+}
+
+type Store struct {
+	Depot Depot `json:"depot"`
+}
+
+func TestRegisterType(t *testing.T) {
+	validtr := NewValidStruct(NewValidationMapper())
+	validtr.RegisterType(Product{}, Warehouse{}, Store{})
+
+	t.Log("\nTesting a type name taken by another type is reported")
+	{
+		type Product struct {
+			Sku string `json:"sku"`
+		}
+		err := validtr.RegisterType(Product{})
+		if err != nil && strings.Contains(err.Error(), "type name Product of") {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected error of type name Product got %v", failed, err)
+		}
+
+		if err := validtr.ApplyRuleConfig(&RuleConfig{Types: map[string]map[string]string{"validator.Product": {"code": "funcVal:Required"}}}); err == nil {
+			checkErrorMessages(t, validtr.Valid(Product{}), []string{})
+		} else {
+			t.Errorf("%s expected error nil got %s", failed, err.Error())
+		}
+	}
+
+	t.Log("\nTesting a nested field given different rules by two types is rejected")
+	{
+		err := validtr.ApplyRuleConfig(&RuleConfig{Types: map[string]map[string]string{
+			"Warehouse": {"depot.city": "funcVal:Required"},
+			"Store":     {"depot.city": "funcVal:MinLength,values:3"},
+		}})
+		if err != nil && strings.Contains(err.Error(), "Store.depot.city and Warehouse.depot.city configure field City") {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected error of field City got %v", failed, err)
+		}
+
+		err = validtr.ApplyRuleConfig(&RuleConfig{Types: map[string]map[string]string{
+			"Warehouse": {"depot.city": "funcVal:Required"},
+			"Store":     {"depot.city": "funcVal:Required"},
+		}})
+		if err == nil {
+			checkErrorMessages(t, validtr.Valid(Store{}), []string{"city is required"})
+		} else {
+			t.Errorf("%s expected error nil got %s", failed, err.Error())
+		}
+	}
+}
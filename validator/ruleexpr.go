@@ -0,0 +1,360 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// RuleExpr is a rule expression, funcVals combined with | (or), & (and), ! (not) and parentheses, like
+// funcVal:Email|Phone or funcVal:!Match:^[0-9]+$&MinLength:3. A funcVal takes its argument after a colon,
+// quoted with ' when it holds an operator, a parenthesis or a space, like AcceptedValues:'gold|silver'.
+// The argument is the format of Match, Type and Date, the compareKey of AfterDate and the values of other funcVals.
+// ! binds tighter than &, and & tighter than |.
+type RuleExpr struct {
+	// Op is |, & or ! for an operator, empty for a funcVal
+	Op       string
+	Operands []*RuleExpr
+	FuncVal  string
+	Arg      string
+}
+
+// IsRuleExpr reports whether funcVal of a rule is a rule expression instead of the name of one funcVal
+func IsRuleExpr(funcVal string) bool {
+	return funcVal != "" && !identifierRegex.MatchString(funcVal)
+}
+
+// ParseRuleExpr parses text, the funcVal of a rule, into a RuleExpr
+func ParseRuleExpr(text string) (*RuleExpr, error) {
+	p := &ruleExprParser{text: text}
+	expr, err := p.parseOr()
+	if err == nil && p.skipSpace() < len(text) {
+		err = fmt.Errorf("unexpected %q at %d", text[p.pos], p.pos)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid rule expression %s: %s", text, err.Error())
+	}
+	return expr, nil
+}
+
+type parsedRuleExpr struct {
+	expr *RuleExpr
+	err  error
+}
+
+// parsedRuleExpr returns text parsed by ParseRuleExpr, once per text
+func (s *ValidStruct) parsedRuleExpr(text string) (*RuleExpr, error) {
+	s.ruleExprLock.RLock()
+	parsed, found := s.ruleExprs[text]
+	s.ruleExprLock.RUnlock()
+	if found {
+		return parsed.expr, parsed.err
+	}
+
+	expr, err := ParseRuleExpr(text)
+
+	s.ruleExprLock.Lock()
+	if s.ruleExprs == nil {
+		s.ruleExprs = make(map[string]parsedRuleExpr)
+	}
+	s.ruleExprs[text] = parsedRuleExpr{expr: expr, err: err}
+	s.ruleExprLock.Unlock()
+
+	return expr, err
+}
+
+// FuncVals returns the funcVals of e, in the order they are written
+func (e *RuleExpr) FuncVals() []*RuleExpr {
+	if e.Op == "" {
+		return []*RuleExpr{e}
+	}
+	var funcVals []*RuleExpr
+	for _, operand := range e.Operands {
+		funcVals = append(funcVals, operand.FuncVals()...)
+	}
+	return funcVals
+}
+
+// Rule returns the rule of a funcVal of e, with Arg in the attribute the funcVal reads
+func (e *RuleExpr) Rule() Rule {
+	return e.dataTag().rule()
+}
+
+func (e *RuleExpr) dataTag() *dataTag {
+	dtag := &dataTag{funcVal: e.FuncVal}
+	switch e.FuncVal {
+	case "Match", "Type":
+		dtag.format = e.Arg
+	case "Date":
+		dtag.format, dtag.dateLayout = e.Arg, e.Arg
+	case "AfterDate":
+		dtag.compareKey = e.Arg
+	default:
+		dtag.acceptedValues = e.Arg
+	}
+	return dtag
+}
+
+// String writes e back in rule expression syntax
+func (e *RuleExpr) String() string {
+	switch e.Op {
+	case "":
+		if e.Arg == "" {
+			return e.FuncVal
+		}
+		if strings.ContainsAny(e.Arg, "|&!() ") {
+			return e.FuncVal + ":'" + e.Arg + "'"
+		}
+		return e.FuncVal + ":" + e.Arg
+	case "!":
+		return "!" + e.Operands[0].compoundString()
+	}
+
+	operands := make([]string, len(e.Operands))
+	for i, operand := range e.Operands {
+		operands[i] = operand.compoundString()
+	}
+	return strings.Join(operands, e.Op)
+}
+
+// compoundString is String of e in parentheses when e combines operands
+func (e *RuleExpr) compoundString() string {
+	if e.Op == "|" || e.Op == "&" {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+// checkEmpty reports whether e runs against an empty value, when one of its funcVals rejects empty values
+func (e *RuleExpr) checkEmpty() bool {
+	for _, funcVal := range e.FuncVals() {
+		if funcVal.FuncVal == "Required" || funcVal.FuncVal == "NonZero" || funcVal.FuncVal == "Present" {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRuleExprFuncVal returns an error for a funcVal that can not be used in a rule expression
+func checkRuleExprFuncVal(funcVal string) error {
+	if IsStructRule(funcVal) || funcVal == "CondRequired" {
+		return fmt.Errorf("funcVal %s is not supported in a rule expression", funcVal)
+	}
+	return nil
+}
+
+type ruleExprParser struct {
+	text string
+	pos  int
+}
+
+func (p *ruleExprParser) skipSpace() int {
+	for p.pos < len(p.text) && p.text[p.pos] == ' ' {
+		p.pos++
+	}
+	return p.pos
+}
+
+func (p *ruleExprParser) parseOr() (*RuleExpr, error) {
+	return p.parseBinary("|", p.parseAnd)
+}
+
+func (p *ruleExprParser) parseAnd() (*RuleExpr, error) {
+	return p.parseBinary("&", p.parseUnary)
+}
+
+func (p *ruleExprParser) parseBinary(op string, next func() (*RuleExpr, error)) (*RuleExpr, error) {
+	first, err := next()
+	if err != nil {
+		return nil, err
+	}
+
+	operands := []*RuleExpr{first}
+	for p.skipSpace() < len(p.text) && p.text[p.pos] == op[0] {
+		p.pos++
+		operand, err := next()
+		if err != nil {
+			return nil, err
+		}
+		operands = append(operands, operand)
+	}
+
+	if len(operands) == 1 {
+		return first, nil
+	}
+	return &RuleExpr{Op: op, Operands: operands}, nil
+}
+
+func (p *ruleExprParser) parseUnary() (*RuleExpr, error) {
+	if p.skipSpace() >= len(p.text) {
+		return nil, errors.New("unexpected end")
+	}
+
+	switch p.text[p.pos] {
+	case '!':
+		p.pos++
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &RuleExpr{Op: "!", Operands: []*RuleExpr{operand}}, nil
+	case '(':
+		open := p.pos
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.skipSpace() >= len(p.text) || p.text[p.pos] != ')' {
+			return nil, fmt.Errorf("parenthesis at %d is not closed", open)
+		}
+		p.pos++
+		return expr, nil
+	}
+
+	return p.parseFuncVal()
+}
+
+func (p *ruleExprParser) parseFuncVal() (*RuleExpr, error) {
+	start := p.pos
+	for p.pos < len(p.text) && (p.text[p.pos] == '_' || unicode.IsLetter(rune(p.text[p.pos])) || (p.pos > start && unicode.IsDigit(rune(p.text[p.pos])))) {
+		p.pos++
+	}
+	if p.pos == start {
+		return nil, fmt.Errorf("expected funcVal at %d", start)
+	}
+
+	expr := &RuleExpr{FuncVal: p.text[start:p.pos]}
+	if p.pos >= len(p.text) || p.text[p.pos] != ':' {
+		return expr, nil
+	}
+	p.pos++
+
+	if p.pos < len(p.text) && p.text[p.pos] == '\'' {
+		end := strings.IndexByte(p.text[p.pos+1:], '\'')
+		if end < 0 {
+			return nil, fmt.Errorf("quote at %d is not closed", p.pos)
+		}
+		expr.Arg = p.text[p.pos+1 : p.pos+1+end]
+		p.pos += end + 2
+		return expr, nil
+	}
+
+	argStart := p.pos
+	for p.pos < len(p.text) && !strings.ContainsRune("|&() ", rune(p.text[p.pos])) {
+		p.pos++
+	}
+	expr.Arg = p.text[argStart:p.pos]
+	if expr.Arg == "" {
+		return nil, fmt.Errorf("funcVal %s has an empty argument", expr.FuncVal)
+	}
+	return expr, nil
+}
+
+// runRuleExpr runs the rule expression of dtag against fv. When it fails, the error holds the errorMessage of dtag,
+// or a message joining the failed funcVals, like email must be a valid email address or email must be a valid phone number
+// with a catalog. A funcVal without message in the error message map or the catalog is named by its rule, like
+// email must satisfy Email or email must satisfy Phone.
+// Like funcVals, an expression does not check an empty value unless it holds Required or NonZero,
+// and a negated funcVal passes an empty value.
+func (s *ValidStruct) runRuleExpr(locale string, dtag *dataTag, parent, fv reflect.Value, typeName, fieldName, keyName string) error {
+	expr, err := s.parsedRuleExpr(dtag.funcVal)
+	if err != nil {
+		return err
+	}
+	if !expr.checkEmpty() && (!fv.IsValid() || !fv.CanInterface() || IsEmpty(fv.Interface())) {
+		return nil
+	}
+
+	passed, failure, err := s.evalRuleExpr(locale, expr, dtag.sensitive, parent, fv, typeName, fieldName, keyName)
+	if err != nil || passed {
+		return err
+	}
+
+	if dtag.errorMessage != "" {
+		return errors.New(dtag.errorMessage) // also SensitiveMessage of a sensitive field
+	}
+	return errors.New(failure)
+}
+
+// evalRuleExpr reports whether fv passes expr, with the message describing the failed funcVals when it does not.
+// Every operand of & and | is run, so the message names each failed alternative.
+func (s *ValidStruct) evalRuleExpr(locale string, expr *RuleExpr, sensitive bool, parent, fv reflect.Value, typeName, fieldName, keyName string) (bool, string, error) {
+	switch expr.Op {
+	case "!":
+		if !fv.IsValid() || !fv.CanInterface() || IsEmpty(fv.Interface()) {
+			return true, "", nil // funcVals skip an empty value, it does not fail their negation either
+		}
+		passed, _, err := s.evalRuleExpr(locale, expr.Operands[0], sensitive, parent, fv, typeName, fieldName, keyName)
+		if err != nil || !passed {
+			return true, "", err
+		}
+		rule := expr.Operands[0].String()
+		if message := s.errorMessageKey(locale, typeName, fieldName, keyName, fv, &dataTag{}, "Expression.not", map[string]string{"rule": rule}); message != "" {
+			return false, message, nil
+		}
+		return false, fmt.Sprintf("%s must not satisfy %s", keyName, rule), nil
+	case "&", "|":
+		key, word := "Expression.and", "and"
+		if expr.Op == "|" {
+			key, word = "Expression.or", "or"
+		}
+		if message := s.errorMessageKey(locale, typeName, fieldName, keyName, fv, &dataTag{}, key, nil); message != "" {
+			word = message
+		}
+
+		var failures []string
+		passedAny, passedAll := false, true
+		for _, operand := range expr.Operands {
+			passed, failure, err := s.evalRuleExpr(locale, operand, sensitive, parent, fv, typeName, fieldName, keyName)
+			if err != nil {
+				return false, "", err
+			}
+			passedAny = passedAny || passed
+			passedAll = passedAll && passed
+			if !passed {
+				if operand.Op != "" && operand.Op != "!" && operand.Op != expr.Op {
+					failure = "(" + failure + ")"
+				}
+				failures = append(failures, failure)
+			}
+		}
+
+		if (expr.Op == "&" && passedAll) || (expr.Op == "|" && passedAny) {
+			return true, "", nil
+		}
+		return false, strings.Join(failures, " "+word+" "), nil
+	}
+
+	if err := checkRuleExprFuncVal(expr.FuncVal); err != nil {
+		return false, "", err
+	}
+	ival, err := s.mapper.GetFunc(expr.FuncVal)
+	if err != nil {
+		return false, "", err
+	}
+
+	dtag := expr.dataTag()
+	dtag.sensitive = sensitive
+	if dtag.funcVal == "Match" {
+		if dtag.format, err = s.resolvePattern(dtag.format); err != nil {
+			return false, "", err
+		}
+	}
+	dtag.errorMessage = s.errorMessage(locale, typeName, fieldName, keyName, fv, dtag)
+
+	if err := s.callFunc(ival, dtag, parent, fv, keyName); err != nil {
+		if dtag.errorMessage != "" {
+			return false, err.Error(), nil
+		}
+		// the default message of a funcVal, like has invalid format value, does not tell the alternatives apart
+		rule := expr.String()
+		if message := s.errorMessageKey(locale, typeName, fieldName, keyName, fv, &dataTag{}, "Expression.rule", map[string]string{"rule": rule}); message != "" {
+			return false, message, nil
+		}
+		return false, fmt.Sprintf("%s must satisfy %s", keyName, rule), nil
+	}
+	return true, "", nil
+}
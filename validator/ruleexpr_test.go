@@ -0,0 +1,105 @@
+package validator
+
+import (
+	"testing"
+)
+
+type ContactForm struct {
+	Contact  string `json:"contact" valid:"funcVal:Required;funcVal:Email|Phone"`
+	Handle   string `json:"handle" valid:"funcVal:!Match:^[0-9]+$&MinLength:3"`
+	Tier     string `json:"tier" valid:"funcVal:AcceptedValues:'gold|silver'|(Match:^vip-&MaxLength:10),errorMessage:{field} {value} is not offered"`
+	Referral string `json:"referral" valid:"funcVal:Email|Match:^@[a-z]+$"`
+	Code     string `json:"code" valid:"sensitive;funcVal:Match:^[A-Z]{4}$|Match:^[0-9]{6}$"`
+}
+
+func TestRuleExpr(t *testing.T) {
+	validtr := NewValidStruct(NewValidationMapper())
+
+	t.Log("\nTesting rule expressions passing")
+	{
+		form := ContactForm{Contact: "081234567890", Handle: "jane", Tier: "vip-gold", Referral: "@jane", Code: "123456"}
+		if errs := validtr.Valid(form); len(errs) == 0 {
+			t.Logf("%s expected no error", success)
+		} else {
+			t.Errorf("%s expected no error got %v", failed, errs)
+		}
+
+		form.Tier = "silver"
+		form.Referral = ""
+		if errs := validtr.Valid(form); len(errs) == 0 {
+			t.Logf("%s expected an empty referral to be skipped", success)
+		} else {
+			t.Errorf("%s expected no error got %v", failed, errs)
+		}
+	}
+
+	t.Log("\nTesting rule expressions parsed once per text")
+	{
+		validtr.Valid(ContactForm{Contact: "jane@example.com", Handle: "jane", Tier: "gold", Referral: "@jane", Code: "ABCD"})
+		validtr.ruleExprLock.RLock()
+		parsed := len(validtr.ruleExprs)
+		validtr.ruleExprLock.RUnlock()
+		if parsed == 5 {
+			t.Logf("%s expected 5 parsed rule expressions", success)
+		} else {
+			t.Errorf("%s expected 5 parsed rule expressions got %d", failed, parsed)
+		}
+	}
+
+	t.Log("\nTesting messages of failed alternatives")
+	{
+		form := ContactForm{Contact: "jane", Handle: "12", Tier: "vip-platinum", Referral: "nope", Code: "abc"}
+		checkFieldErrors(t, validtr.ValidLocale(form, "en"), []string{
+			"contact Email|Phone: contact must be a valid email address or contact must be a valid phone number",
+			"handle !Match:^[0-9]+$&MinLength:3: handle must not satisfy Match:^[0-9]+$ and handle length must be at least 3",
+			"tier AcceptedValues:'gold|silver'|(Match:^vip-&MaxLength:10): tier vip-platinum is not offered",
+			"referral Email|Match:^@[a-z]+$: referral must be a valid email address or referral has invalid format value",
+			"code Match:^[A-Z]{4}$|Match:^[0-9]{6}$: code is invalid",
+		})
+
+		checkFieldErrors(t, validtr.ValidLocale(ContactForm{Contact: "jane", Handle: "jane"}, "id"), []string{
+			"contact Email|Phone: contact harus berupa alamat email yang valid atau contact harus berupa nomor telepon yang valid",
+		})
+	}
+
+	t.Log("\nTesting failed alternatives without catalog are named by their rule")
+	{
+		checkFieldErrors(t, validtr.ValidLocale(ContactForm{Contact: "xx", Handle: "jane"}, ""), []string{
+			"contact Email|Phone: contact must satisfy Email or contact must satisfy Phone",
+		})
+	}
+
+	t.Log("\nTesting Required in an expression checks empty values")
+	{
+		rules := map[string]string{"nick": "funcVal:Required&!Match:^admin$"}
+		checkFieldErrors(t, validtr.ValidMapLocale(map[string]interface{}{"nick": ""}, rules, "en"), []string{"nick Required&!Match:^admin$: nick is required"})
+		checkFieldErrors(t, validtr.ValidMapLocale(map[string]interface{}{"nick": "admin"}, rules, "en"), []string{"nick Required&!Match:^admin$: nick must not satisfy Match:^admin$"})
+	}
+
+	t.Log("\nTesting parsing rule expressions")
+	{
+		expr, err := ParseRuleExpr("!(Email | Phone) & Match:'^[a-z ]+$'")
+		if err == nil && expr.String() == "!(Email|Phone)&Match:'^[a-z ]+$'" && len(expr.FuncVals()) == 3 && expr.FuncVals()[2].Rule().Format == "^[a-z ]+$" {
+			t.Logf("%s expected expression %s", success, expr.String())
+		} else {
+			t.Errorf("%s expected expression !(Email|Phone)&Match:'^[a-z ]+$' got %v and %v", failed, expr, err)
+		}
+
+		for _, text := range []string{"Email|", "(Email", "Match:'abc", "Email Phone", "MinLength:"} {
+			if _, err := ParseRuleExpr(text); err != nil {
+				t.Logf("%s expected error %s", success, err.Error())
+			} else {
+				t.Errorf("%s expected error parsing %s", failed, text)
+			}
+		}
+
+		validtr.RegisterType(ContactForm{})
+		for _, rules := range []string{"funcVal:Email|Password", "funcVal:Email|Unknown", "funcVal:Match:'('|Email"} {
+			if err := validtr.ApplyRuleConfig(&RuleConfig{Types: map[string]map[string]string{"ContactForm": {"contact": rules}}}); err != nil {
+				t.Logf("%s expected error %s", success, err.Error())
+			} else {
+				t.Errorf("%s expected error registering %s", failed, rules)
+			}
+		}
+	}
+}
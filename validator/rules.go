@@ -0,0 +1,308 @@
+package validator
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// registeredRule is a rule set attached to a struct field outside of its valid tag
+type registeredRule struct {
+	rules    string
+	override bool
+}
+
+// RegisterRules attaches rules to the fields of the struct type of sample, for types that can not carry valid tag.
+// rules is keyed by struct field name or json name and holds the same syntax as valid tag.
+// The rules are run after the rules in valid tag of the field.
+func (s *ValidStruct) RegisterRules(sample interface{}, rules map[string]string) error {
+	return s.registerRules(sample, rules, false)
+}
+
+// OverrideRules attaches rules like RegisterRules, but the rules replace the valid tag of the field
+func (s *ValidStruct) OverrideRules(sample interface{}, rules map[string]string) error {
+	return s.registerRules(sample, rules, true)
+}
+
+func (s *ValidStruct) registerRules(sample interface{}, rules map[string]string, override bool) error {
+	t := reflect.TypeOf(sample)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return errors.New("register rules only accept sample type struct")
+	}
+
+	fieldRules := make(map[string]registeredRule, len(rules))
+	for key, rule := range rules {
+		ft, found := fieldByKey(t, key)
+		if !found {
+			return fmt.Errorf("field %s is not found in %s", key, t)
+		}
+		fieldRules[ft.Name] = registeredRule{rules: rule, override: override}
+	}
+
+	s.ruleLock.Lock()
+	defer s.ruleLock.Unlock()
+
+	if s.typeRules == nil {
+		s.typeRules = make(map[reflect.Type]map[string]registeredRule)
+	}
+	if s.typeRules[t] == nil {
+		s.typeRules[t] = make(map[string]registeredRule)
+	}
+	for name, rule := range fieldRules {
+		s.typeRules[t][name] = rule
+	}
+
+	return nil
+}
+
+// fieldRules returns the rules of field ft of struct type t, merging valid tag,
+// rules added with RegisterRules and rules loaded from configuration, in that order
+func (s *ValidStruct) fieldRules(t reflect.Type, ft reflect.StructField) string {
+	dtags := ft.Tag.Get("valid")
+
+	s.ruleLock.RLock()
+	rule, found := s.typeRules[t][ft.Name]
+	configRule, configFound := s.configRules[t][ft.Name]
+	s.ruleLock.RUnlock()
+
+	if found {
+		dtags = rule.merge(dtags)
+	}
+	if configFound {
+		dtags = configRule.merge(dtags)
+	}
+
+	return dtags
+}
+
+func (r registeredRule) merge(dtags string) string {
+	if r.override || dtags == "" {
+		return r.rules
+	}
+	if r.rules == "" {
+		return dtags
+	}
+	return dtags + ";" + r.rules
+}
+
+// fieldByKey finds the field of struct type t named key, either by its name or by its json name
+func fieldByKey(t reflect.Type, key string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+		jsonName := strings.TrimSpace(strings.Split(ft.Tag.Get("json"), ",")[0])
+		if ft.Name == key || (jsonName != "" && jsonName == key) {
+			return ft, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// RuleBuilder builds rules for RegisterRules in code, like
+//
+//	rules := NewRuleBuilder()
+//	rules.Field("Email").Required().Email()
+//	rules.Field("Age").AcceptedValues("17<->60")
+//	validtr.RegisterRules(User{}, rules.Rules())
+type RuleBuilder struct {
+	fields map[string][]*dataTag
+}
+
+func NewRuleBuilder() *RuleBuilder {
+	return &RuleBuilder{fields: make(map[string][]*dataTag)}
+}
+
+// Field starts the rules of field name, rules added before for the same field are kept
+func (b *RuleBuilder) Field(name string) *FieldRuleBuilder {
+	if _, found := b.fields[name]; !found {
+		b.fields[name] = []*dataTag{}
+	}
+	return &FieldRuleBuilder{builder: b, name: name}
+}
+
+// Rules returns the rules in valid tag syntax keyed by field name
+func (b *RuleBuilder) Rules() map[string]string {
+	rules := make(map[string]string, len(b.fields))
+	for name, dtags := range b.fields {
+		rules[name] = formatDataTags(dtags)
+	}
+	return rules
+}
+
+// FieldRuleBuilder adds funcVals to one field of a RuleBuilder
+type FieldRuleBuilder struct {
+	builder *RuleBuilder
+	name    string
+}
+
+// Field continues with the rules of another field
+func (f *FieldRuleBuilder) Field(name string) *FieldRuleBuilder {
+	return f.builder.Field(name)
+}
+
+// Func adds funcVal by name, used for validators added with RegisterValidator
+func (f *FieldRuleBuilder) Func(funcVal string) *FieldRuleBuilder {
+	return f.add(&dataTag{funcVal: funcVal})
+}
+
+// Message sets the error message of the last added funcVal
+func (f *FieldRuleBuilder) Message(message string) *FieldRuleBuilder {
+	if dtags := f.builder.fields[f.name]; len(dtags) > 0 {
+		dtags[len(dtags)-1].errorMessage = message
+	}
+	return f
+}
+
+func (f *FieldRuleBuilder) Required() *FieldRuleBuilder {
+	return f.Func("Required")
+}
+
+func (f *FieldRuleBuilder) Email() *FieldRuleBuilder {
+	return f.Func("Email")
+}
+
+func (f *FieldRuleBuilder) Phone() *FieldRuleBuilder {
+	return f.Func("Phone")
+}
+
+func (f *FieldRuleBuilder) Url() *FieldRuleBuilder {
+	return f.Func("Url")
+}
+
+func (f *FieldRuleBuilder) Match(format string) *FieldRuleBuilder {
+	return f.add(&dataTag{funcVal: "Match", format: format})
+}
+
+func (f *FieldRuleBuilder) Date(format, layout string) *FieldRuleBuilder {
+	return f.add(&dataTag{funcVal: "Date", format: format, dateLayout: layout})
+}
+
+// AcceptedValues takes values separated by | or a range separated by <->
+func (f *FieldRuleBuilder) AcceptedValues(values string) *FieldRuleBuilder {
+	return f.add(&dataTag{funcVal: "AcceptedValues", acceptedValues: values})
+}
+
+// CondRequired takes compareValue separated by |
+func (f *FieldRuleBuilder) CondRequired(compareKey, compareValue string) *FieldRuleBuilder {
+	return f.add(&dataTag{funcVal: "CondRequired", compareKey: compareKey, compareValue: compareValue})
+}
+
+func (f *FieldRuleBuilder) AfterDate(compareKey string) *FieldRuleBuilder {
+	return f.add(&dataTag{funcVal: "AfterDate", compareKey: compareKey})
+}
+
+// Password takes the name of a policy registered with RegisterPasswordPolicy, empty for the default policy,
+// and compareKey, the field the password must not contain, or empty
+func (f *FieldRuleBuilder) Password(policy, compareKey string) *FieldRuleBuilder {
+	return f.add(&dataTag{funcVal: "Password", format: policy, compareKey: compareKey})
+}
+
+// Expr adds an expr rule, expression must give true, like Discount <= Price * 0.5
+func (f *FieldRuleBuilder) Expr(expression string) *FieldRuleBuilder {
+	return f.add(&dataTag{expr: expression})
+}
+
+// OmitEmpty skips every rule of the field when it holds no value, see IsUnset
+func (f *FieldRuleBuilder) OmitEmpty() *FieldRuleBuilder {
+	return f.add(&dataTag{omitEmpty: true})
+}
+
+func (f *FieldRuleBuilder) NonZero() *FieldRuleBuilder {
+	return f.Func("NonZero")
+}
+
+// Sensitive redacts the value of the field from error messages
+func (f *FieldRuleBuilder) Sensitive() *FieldRuleBuilder {
+	return f.add(&dataTag{sensitive: true})
+}
+
+func (f *FieldRuleBuilder) add(dtag *dataTag) *FieldRuleBuilder {
+	f.builder.fields[f.name] = append(f.builder.fields[f.name], dtag)
+	return f
+}
+
+// formatDataTags writes dtags back in valid tag syntax, quoting the values holding a separator
+func formatDataTags(dtags []*dataTag) string {
+	rules := make([]string, 0, len(dtags))
+	for _, dtag := range dtags {
+		if dtag.sensitive {
+			rules = append(rules, SensitiveOption)
+			continue
+		}
+		if dtag.omitEmpty {
+			rules = append(rules, OmitEmptyOption)
+			continue
+		}
+		attrs := []string{"funcVal:" + dtag.funcVal}
+		if dtag.expr != "" {
+			attrs = []string{"expr:" + dtag.expr}
+		}
+		if dtag.format != "" {
+			attrs = append(attrs, "format:"+QuoteTagValue(dtag.format))
+		}
+		if dtag.dateLayout != "" {
+			attrs = append(attrs, "dateLayout:"+QuoteTagValue(dtag.dateLayout))
+		}
+		if dtag.compareKey != "" {
+			attrs = append(attrs, "compareKey:"+QuoteTagValue(dtag.compareKey))
+		}
+		if dtag.compareValue != "" {
+			attrs = append(attrs, "compareValue:"+QuoteTagValue(dtag.compareValue))
+		}
+		if dtag.acceptedValues != "" {
+			attrs = append(attrs, "values:"+QuoteTagValue(dtag.acceptedValues))
+		}
+		if dtag.errorMessage != "" {
+			attrs = append(attrs, "errorMessage:"+QuoteTagValue(dtag.errorMessage))
+		}
+		rules = append(rules, strings.Join(attrs, ","))
+	}
+	return strings.Join(rules, ";")
+}
+
+// Rule is one funcVal of a rule set with its attributes, as written in valid tag
+type Rule struct {
+	FuncVal      string
+	ErrorMessage string
+	Format       string
+	CompareKey   string
+	CompareValue string
+	DateLayout   string
+	Values       string
+	// Expr is the expression of an expr rule, which has no FuncVal
+	Expr string
+	// Sensitive is set for the sensitive option, a rule without funcVal
+	Sensitive bool
+	// OmitEmpty is set for the omitempty option, a rule without funcVal
+	OmitEmpty bool
+}
+
+// ParseRules splits rules in valid tag syntax into its funcVals
+func ParseRules(rules string) []Rule {
+	dataTags := []*dataTag{}
+	dataTags = fetchDataTag(rules, -1, dataTags)
+
+	result := make([]Rule, 0, len(dataTags))
+	for _, dtag := range dataTags {
+		result = append(result, dtag.rule())
+	}
+	return result
+}
+
+func (d *dataTag) rule() Rule {
+	return Rule{
+		FuncVal:      d.funcVal,
+		ErrorMessage: d.errorMessage,
+		Format:       d.format,
+		CompareKey:   d.compareKey,
+		CompareValue: d.compareValue,
+		DateLayout:   d.dateLayout,
+		Values:       d.acceptedValues,
+		Expr:         d.expr,
+		Sensitive:    d.sensitive,
+		OmitEmpty:    d.omitEmpty,
+	}
+}
@@ -0,0 +1,117 @@
+package validator
+
+import (
+	"testing"
+)
+
+// Agent simulates a generated type that carries no valid tag
+type Agent struct {
+	Nis    string `json:"nis"`
+	Name   string `json:"name" valid:"funcVal:Required"`
+	Email  string `json:"email"`
+	Status string `json:"status"`
+	Reason string `json:"reason"`
+}
+
+func TestRuleBuilder(t *testing.T) {
+	t.Log("\nTesting rule builder output")
+	{
+		rules := NewRuleBuilder()
+		rules.Field("Email").Required().Email().Message("email is invalid").
+			Field("Reason").CondRequired("status", "rejected")
+
+		result := rules.Rules()
+		expected := map[string]string{
+			"Email":  "funcVal:Required;funcVal:Email,errorMessage:email is invalid",
+			"Reason": "funcVal:CondRequired,compareKey:status,compareValue:rejected",
+		}
+		for k, v := range expected {
+			if result[k] == v {
+				t.Logf("%s expected %s", success, v)
+			} else {
+				t.Errorf("%s expected %s got %s", failed, v, result[k])
+			}
+		}
+	}
+
+	t.Log("\nTesting rule builder quotes values holding separators")
+	{
+		rules := NewRuleBuilder()
+		rules.Field("Code").Match("^[a-z]{2,4}$").Message("{field} must be 2, 3 or 4 letters; lowercase").
+			Field("Name").Required().Message("'name' is required")
+
+		result := rules.Rules()
+		expected := "funcVal:Match,format:'^[a-z]{2,4}$',errorMessage:'{field} must be 2, 3 or 4 letters; lowercase'"
+		if result["Code"] == expected {
+			t.Logf("%s expected %s", success, expected)
+		} else {
+			t.Errorf("%s expected %s got %s", failed, expected, result["Code"])
+		}
+
+		parsed := ParseRules(result["Name"])
+		if len(parsed) == 1 && parsed[0].ErrorMessage == "'name' is required" {
+			t.Logf("%s expected message 'name' is required", success)
+		} else {
+			t.Errorf("%s expected message 'name' is required got %+v", failed, parsed)
+		}
+
+		validtr := NewValidStruct(NewValidationMapper())
+		data := map[string]interface{}{"Code": "abc", "Name": "jane"}
+		if errs := validtr.ValidMap(data, result); len(errs) == 0 {
+			t.Logf("%s expected abc to match ^[a-z]{2,4}$", success)
+		} else {
+			t.Errorf("%s expected abc to match ^[a-z]{2,4}$ got %v", failed, errs)
+		}
+
+		data = map[string]interface{}{"Code": "abcde", "Name": "jane"}
+		checkErrorMessages(t, validtr.ValidMap(data, result), []string{"Code must be 2, 3 or 4 letters; lowercase"})
+	}
+}
+
+func TestValidStruct_RegisterRules(t *testing.T) {
+	agent := Agent{Email: "agent@example", Status: "rejected"}
+
+	t.Log("\nTesting registered rules merged with tag")
+	{
+		validtr := NewValidStruct(NewValidationMapper())
+		err := validtr.RegisterRules(Agent{}, map[string]string{
+			"nis":  "funcVal:Required",
+			"Name": "funcVal:Match,format:^[A-Z]",
+		})
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+
+		rules := NewRuleBuilder()
+		rules.Field("email").Email().Field("reason").CondRequired("status", "rejected")
+		if err := validtr.RegisterRules(&Agent{}, rules.Rules()); err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+
+		checkErrorMessages(t, validtr.Valid(agent), []string{
+			"nis is required",
+			"name is required",
+			"email has invalid format value",
+			"reason is required",
+		})
+	}
+
+	t.Log("\nTesting override rules replace tag")
+	{
+		validtr := NewValidStruct(NewValidationMapper())
+		if err := validtr.OverrideRules(Agent{}, map[string]string{"name": ""}); err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+		checkErrorMessages(t, validtr.Valid(agent), []string{})
+	}
+
+	t.Log("\nTesting register rules of unknown field")
+	{
+		validtr := NewValidStruct(NewValidationMapper())
+		if err := validtr.RegisterRules(Agent{}, map[string]string{"Address": "funcVal:Required"}); err != nil {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected error not nil", failed)
+		}
+	}
+}
@@ -0,0 +1,304 @@
+package validator
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const JSONSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// Schema is a JSON Schema (draft 2020-12) document, also usable as OpenAPI 3 schema object
+type Schema struct {
+	Schema      string             `json:"$schema,omitempty"`
+	Title       string             `json:"title,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	Enum        []interface{}      `json:"enum,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+	MaxLength   *int               `json:"maxLength,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	// Ref refers to the schema of a recursive type, described once in Defs
+	Ref  string             `json:"$ref,omitempty"`
+	Defs map[string]*Schema `json:"$defs,omitempty"`
+}
+
+// SchemaHook maps a funcVal into schema keywords, it is called with the schema of the field holding the rule
+type SchemaHook func(rule Rule, schema *Schema)
+
+// RegisterSchemaHook sets the hook used to describe funcVal in generated schema,
+// for validators added with RegisterValidator or to change how a default funcVal is described
+func (s *ValidStruct) RegisterSchemaHook(funcVal string, hook SchemaHook) {
+	s.schemaLock.Lock()
+	if s.schemaHooks == nil {
+		s.schemaHooks = make(map[string]SchemaHook)
+	}
+	s.schemaHooks[funcVal] = hook
+	s.schemaLock.Unlock()
+}
+
+// schemaContext holds the state of one schema generation
+type schemaContext struct {
+	// visiting holds the struct types being described, to stop on recursive types
+	visiting map[reflect.Type]bool
+	// refs holds the reference of the types described elsewhere, like the root type
+	refs map[reflect.Type]string
+	// defs holds the schemas of the other recursive types, referred by defsPrefix and their name
+	defs       map[string]*Schema
+	defsPrefix string
+}
+
+func newSchemaContext(defsPrefix string) *schemaContext {
+	return &schemaContext{
+		visiting:   make(map[reflect.Type]bool),
+		refs:       make(map[reflect.Type]string),
+		defs:       make(map[string]*Schema),
+		defsPrefix: defsPrefix,
+	}
+}
+
+// JSONSchema generates the JSON Schema of the struct type of sample from its valid and json tags.
+// Rules added with RegisterRules or loaded from configuration are included, except rule expressions,
+// expr rules and the omitempty option, which have no schema keyword. A recursive type is referred with $ref,
+// the root type as # and the others in $defs.
+func (s *ValidStruct) JSONSchema(sample interface{}) (*Schema, error) {
+	t, err := sampleStructType(sample)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := newSchemaContext("#/$defs/")
+	ctx.refs[t] = "#"
+	schema := s.typeSchema(t, ctx)
+	schema.Schema = JSONSchemaDraft
+	schema.Title = t.Name()
+	if len(ctx.defs) > 0 {
+		schema.Defs = ctx.defs
+	}
+
+	return schema, nil
+}
+
+// OpenAPISchemas generates the schemas of samples keyed by type name, to be put in components.schemas of an OpenAPI 3 document.
+// A recursive type is referred with $ref into components.schemas, where it is added when it is not one of samples.
+func (s *ValidStruct) OpenAPISchemas(samples ...interface{}) (map[string]*Schema, error) {
+	ctx := newSchemaContext("#/components/schemas/")
+	types := make([]reflect.Type, 0, len(samples))
+	for _, sample := range samples {
+		t, err := sampleStructType(sample)
+		if err != nil {
+			return nil, err
+		}
+		ctx.refs[t] = ctx.defsPrefix + t.Name()
+		types = append(types, t)
+	}
+
+	schemas := make(map[string]*Schema, len(samples))
+	for _, t := range types {
+		schemas[t.Name()] = s.typeSchema(t, ctx)
+	}
+	for name, schema := range ctx.defs {
+		if _, found := schemas[name]; !found {
+			schemas[name] = schema
+		}
+	}
+	return schemas, nil
+}
+
+func sampleStructType(sample interface{}) (reflect.Type, error) {
+	t := reflect.TypeOf(sample)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil, errors.New("schema only accept sample type struct")
+	}
+	return t, nil
+}
+
+// typeSchema describes t, a struct type already being described is referred with $ref
+func (s *ValidStruct) typeSchema(t reflect.Type, ctx *schemaContext) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t == durationType:
+		return &Schema{Type: "integer"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.typeSchema(t.Elem(), ctx)}
+	case reflect.Map:
+		return &Schema{Type: "object"}
+	case reflect.Struct:
+		if ctx.visiting[t] {
+			ref, found := ctx.refs[t]
+			if !found {
+				ref = ctx.defsPrefix + t.Name()
+				ctx.refs[t] = ref
+				ctx.defs[t.Name()] = nil // described when its outer schema is done
+			}
+			return &Schema{Ref: ref}
+		}
+		ctx.visiting[t] = true
+		schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+		s.structProperties(t, schema, ctx)
+		delete(ctx.visiting, t)
+
+		if described, found := ctx.defs[t.Name()]; found && described == nil {
+			ctx.defs[t.Name()] = schema
+			return &Schema{Ref: ctx.refs[t]}
+		}
+		return schema
+	}
+
+	return &Schema{}
+}
+
+// structProperties adds the fields of t to schema. A Required string gets minLength 1, as the validator rejects an empty string,
+// and a field with the omitempty option is not required.
+func (s *ValidStruct) structProperties(t reflect.Type, schema *Schema, ctx *schemaContext) {
+	for i := 0; i < t.NumField(); i++ {
+		ft := t.Field(i)
+		if ft.PkgPath != "" && !ft.Anonymous {
+			continue // unexported field
+		}
+
+		jsplit := strings.Split(ft.Tag.Get("json"), ",")
+		name := strings.TrimSpace(jsplit[0])
+		if name == "-" {
+			continue
+		}
+
+		if ft.Anonymous && name == "" && isNestedStruct(ft.Type) {
+			s.structProperties(ft.Type, schema, ctx) // embedded fields are flattened like encoding/json does
+			continue
+		}
+
+		if name == "" {
+			name = ft.Name
+		}
+
+		property := s.typeSchema(ft.Type, ctx)
+		rules := s.fieldRules(t, ft)
+		for _, rule := range ParseRules(rules) {
+			if rule.FuncVal == "Required" {
+				if IsOmitEmpty(rules) {
+					continue // an unset value skips Required
+				}
+				schema.Required = append(schema.Required, name)
+				if property.Type == "string" && property.Format == "" && (property.MinLength == nil || *property.MinLength < 1) {
+					minLength := 1
+					property.MinLength = &minLength
+				}
+				continue
+			}
+			s.applySchemaRule(rule, property)
+		}
+		schema.Properties[name] = property
+	}
+}
+
+func (s *ValidStruct) applySchemaRule(rule Rule, schema *Schema) {
+	s.schemaLock.RLock()
+	hook, found := s.schemaHooks[rule.FuncVal]
+	s.schemaLock.RUnlock()
+
+	if found {
+		hook(rule, schema)
+		return
+	}
+
+	switch rule.FuncVal {
+	case "Email":
+		schema.Format = "email"
+	case "Url":
+		schema.Format = "uri"
+	case "Phone":
+		schema.Pattern = s.PhoneFormat
+	case "Match":
+		if pattern, err := s.resolvePattern(rule.Format); err == nil {
+			schema.Pattern = pattern
+		}
+	case "Date":
+		if rule.DateLayout == "2006-01-02" {
+			schema.Format = "date"
+		} else {
+			format := rule.Format
+			if format == "" {
+				format = s.DateFormat
+			}
+			schema.Description = "date of format " + format
+		}
+	case "Type":
+		schema.Type = rule.Format
+	case "Min", "Max":
+		if limit, err := strconv.ParseFloat(rule.Values, 64); err == nil {
+			if rule.FuncVal == "Min" {
+				schema.Minimum = &limit
+			} else {
+				schema.Maximum = &limit
+			}
+		}
+	case "MinLength", "MaxLength":
+		if limit, err := strconv.Atoi(rule.Values); err == nil {
+			if rule.FuncVal == "MinLength" {
+				schema.MinLength = &limit
+			} else {
+				schema.MaxLength = &limit
+			}
+		}
+	case "AcceptedValues":
+		if strings.Contains(rule.Values, "<->") {
+			bounds := strings.Split(rule.Values, "<->")
+			if min, err := strconv.ParseFloat(bounds[0], 64); err == nil {
+				schema.Minimum = &min
+			}
+			if max, err := strconv.ParseFloat(bounds[1], 64); err == nil {
+				schema.Maximum = &max
+			}
+		} else if rule.Values != "" {
+			for _, value := range strings.Split(rule.Values, "|") {
+				schema.Enum = append(schema.Enum, schemaEnumValue(schema.Type, value))
+			}
+		}
+	}
+}
+
+// schemaEnumValue converts value into the json type of the schema
+func schemaEnumValue(schemaType, value string) interface{} {
+	switch schemaType {
+	case "integer":
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return i
+		}
+	case "number":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return value
+}
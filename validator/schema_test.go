@@ -0,0 +1,104 @@
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+type Voucher struct {
+	Code   string   `json:"code" valid:"funcVal:Required;funcVal:Match,format:^[VE]V-[0-9]{3}$"`
+	Type   string   `json:"type" valid:"funcVal:AcceptedValues,values:e-voucher|giftcard"`
+	Amount int      `json:"amount" valid:"funcVal:AcceptedValues,values:1000<->500000"`
+	Owner  string   `json:"owner" valid:"funcVal:Email"`
+	Note   string   `json:"-"`
+	Items  []Item   `json:"items" valid:"funcVal:Required"`
+	Parent *Voucher `json:"parent,omitempty"`
+}
+
+type Item struct {
+	Sku      string `json:"sku" valid:"funcVal:Required;funcVal:Sku"`
+	Quantity uint   `json:"qty" valid:"funcVal:AcceptedValues,values:1|2|3"`
+}
+
+type Category struct {
+	Name     string     `json:"name" valid:"funcVal:Required"`
+	Children []Category `json:"children"`
+}
+
+type Catalog struct {
+	Root    Category `json:"root"`
+	Contact string   `json:"contact" valid:"omitempty;funcVal:Required;funcVal:Email|Phone"`
+}
+
+func TestValidStruct_JSONSchema(t *testing.T) {
+	t.Log("\nTesting json schema of voucher")
+	{
+		validtr := NewValidStruct(NewValidationMapper())
+		validtr.RegisterSchemaHook("Sku", func(rule Rule, schema *Schema) {
+			schema.Pattern = "^SKU-[0-9]+$"
+		})
+
+		schema, err := validtr.JSONSchema(&Voucher{})
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+
+		result, _ := json.Marshal(schema)
+		expected := `{"$schema":"https://json-schema.org/draft/2020-12/schema","title":"Voucher","type":"object",` +
+			`"properties":{"amount":{"type":"integer","minimum":1000,"maximum":500000},` +
+			`"code":{"type":"string","pattern":"^[VE]V-[0-9]{3}$","minLength":1},` +
+			`"items":{"type":"array","items":{"type":"object","properties":{"qty":{"type":"integer","enum":[1,2,3]},"sku":{"type":"string","pattern":"^SKU-[0-9]+$","minLength":1}},"required":["sku"]}},` +
+			`"owner":{"type":"string","format":"email"},` +
+			`"parent":{"$ref":"#"},` +
+			`"type":{"type":"string","enum":["e-voucher","giftcard"]}},` +
+			`"required":["code","items"]}`
+
+		if string(result) == expected {
+			t.Logf("%s expected %s", success, expected)
+		} else {
+			t.Errorf("%s expected %s got %s", failed, expected, string(result))
+		}
+	}
+
+	t.Log("\nTesting recursive types and rules without schema keyword")
+	{
+		validtr := NewValidStruct(NewValidationMapper())
+		schema, err := validtr.JSONSchema(Catalog{})
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+
+		result, _ := json.Marshal(schema)
+		expected := `{"$schema":"https://json-schema.org/draft/2020-12/schema","title":"Catalog","type":"object",` +
+			`"properties":{"contact":{"type":"string"},"root":{"$ref":"#/$defs/Category"}},` +
+			`"$defs":{"Category":{"type":"object","properties":{"children":{"type":"array","items":{"$ref":"#/$defs/Category"}},` +
+			`"name":{"type":"string","minLength":1}},"required":["name"]}}}`
+		if string(result) == expected {
+			t.Logf("%s expected %s", success, expected)
+		} else {
+			t.Errorf("%s expected %s got %s", failed, expected, string(result))
+		}
+
+		schemas, err := validtr.OpenAPISchemas(Catalog{})
+		if err == nil && len(schemas) == 2 && schemas["Catalog"].Properties["root"].Ref == "#/components/schemas/Category" &&
+			schemas["Category"].Properties["children"].Items.Ref == "#/components/schemas/Category" {
+			t.Logf("%s expected Category referred in components.schemas", success)
+		} else {
+			t.Errorf("%s expected Category referred in components.schemas got %v and %v", failed, schemas, err)
+		}
+	}
+
+	t.Log("\nTesting openapi schemas")
+	{
+		validtr := NewValidStruct(NewValidationMapper())
+		schemas, err := validtr.OpenAPISchemas(Voucher{}, Item{})
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+		if len(schemas) == 2 && schemas["Item"] != nil && schemas["Item"].Schema == "" {
+			t.Logf("%s expected schemas Voucher and Item", success)
+		} else {
+			t.Errorf("%s expected schemas Voucher and Item got %v", failed, schemas)
+		}
+	}
+}
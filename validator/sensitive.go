@@ -0,0 +1,33 @@
+package validator
+
+import "reflect"
+
+// SensitiveOption is the valid tag option marking a field holding a secret, like valid:"sensitive;funcVal:Password".
+// The value of a sensitive field never appears in error messages, {value} is replaced with Redacted and a rule
+// whose message would be built by its validation function gets SensitiveMessage instead.
+const SensitiveOption = "sensitive"
+
+// Redacted replaces the value of a sensitive field in error messages
+const Redacted = "[REDACTED]"
+
+// SensitiveMessage is the message of a failed rule of a sensitive field having no message in errorMessage tag,
+// ErrorMessageMap or the catalog of the locale
+const SensitiveMessage = "{field} is invalid"
+
+// IsSensitive reports whether rules, in valid tag syntax, have the sensitive option
+func IsSensitive(rules string) bool {
+	return hasOption(rules, SensitiveOption)
+}
+
+// isSensitiveField reports whether field ft of struct type t has the sensitive option in valid tag or registered rules
+func (s *ValidStruct) isSensitiveField(t reflect.Type, ft reflect.StructField) bool {
+	return IsSensitive(s.fieldRules(t, ft))
+}
+
+// displayValue returns text, or Redacted when sensitive
+func displayValue(text string, sensitive bool) string {
+	if sensitive {
+		return Redacted
+	}
+	return text
+}
@@ -0,0 +1,80 @@
+package validator
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type ApiCredential struct {
+	Key   string `json:"key" valid:"sensitive;funcVal:MinLength,values:32"`
+	Scope string `json:"scope" valid:"sensitive;funcVal:AcceptedValues,values:read|write;funcVal:MaxLength,values:5,errorMessage:{value} is too long"`
+	Pin   int    `json:"pin" valid:"sensitive"`
+	Note  string `json:"note" valid:"funcVal:AcceptedValues,values:a|b"`
+}
+
+func TestSensitive(t *testing.T) {
+	validtr := NewValidStruct(NewValidationMapper())
+	credential := ApiCredential{Key: "sk-live-123", Scope: "admin-all", Note: "visible"}
+
+	t.Log("\nTesting values of sensitive fields are not in messages")
+	{
+		errs := validtr.Valid(credential)
+		expected := []string{"key is invalid", "scope is invalid", "[REDACTED] is too long", "wrong value visible, accepted values a|b"}
+		matched := len(errs) == len(expected)
+		for i := 0; matched && i < len(errs); i++ {
+			matched = errs[i].Error() == expected[i]
+		}
+		if matched {
+			t.Logf("%s expected errors %v", success, errs)
+		} else {
+			t.Errorf("%s expected %v got %v", failed, expected, errs)
+		}
+
+		errs = validtr.ValidLocale(credential, "en")
+		if len(errs) == 4 && errs[0].Error() == "key length must be at least 32" && errs[1].Error() == "scope must be one of read, write" {
+			t.Logf("%s expected catalog messages %v", success, errs)
+		} else {
+			t.Errorf("%s expected catalog messages got %v", failed, errs)
+		}
+	}
+
+	t.Log("\nTesting values of sensitive fields are not in conversion errors")
+	{
+		var dst ApiCredential
+		errs := validtr.BindValues(&dst, url.Values{"pin": {"12ab"}})
+		if len(errs) == 1 && errs[0].Error() == "pin has invalid value [REDACTED]" {
+			t.Logf("%s expected %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected pin has invalid value [REDACTED] got %v", failed, errs)
+		}
+
+		report, err := validtr.ValidCSV(strings.NewReader("key,pin\nsk-live-1234567890123456789012345,12ab\n"), ApiCredential{})
+		if err == nil && len(report.Errors()) == 1 && report.Errors()[0].Error() == "row 2, column pin: invalid value [REDACTED]" {
+			t.Logf("%s expected %s", success, report.Errors()[0].Error())
+		} else {
+			t.Errorf("%s expected redacted csv error got %v and %v", failed, report, err)
+		}
+	}
+
+	t.Log("\nTesting sensitive option of registered rules")
+	{
+		type Token struct {
+			Value string
+		}
+
+		builder := NewRuleBuilder()
+		builder.Field("Value").Sensitive().AcceptedValues("a|b")
+		rules := builder.Rules()
+		if err := validtr.RegisterRules(Token{}, rules); err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+		parsed := ParseRules(rules["Value"])
+		errs := validtr.Valid(Token{Value: "secret"})
+		if rules["Value"] == "sensitive;funcVal:AcceptedValues,values:a|b" && parsed[0].Sensitive && len(errs) == 1 && errs[0].Error() == "Value is invalid" {
+			t.Logf("%s expected %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected Value is invalid got %s and %v", failed, rules["Value"], errs)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Package sqllookup implements validator.Lookup with database/sql, so funcVal Unique and Exists query a database.
+package sqllookup
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/zibilal/structiterator/querycomposer/mysqlquery"
+	"github.com/zibilal/structiterator/validator"
+)
+
+// Querier is the part of *sql.DB, *sql.Conn and *sql.Tx used by Lookup
+type Querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Lookup answers validator lookups with a query built by the querycomposer select builder, like
+// SELECT 1 FROM users WHERE email = ? LIMIT 1. The placeholder is ?, as used by MySQL and SQLite drivers.
+type Lookup struct {
+	db Querier
+}
+
+// New creates a Lookup querying db
+func New(db Querier) *Lookup {
+	return &Lookup{db: db}
+}
+
+func (l *Lookup) Exists(ctx context.Context, table, column string, value interface{}) (bool, error) {
+	// table and column come from valid tag, they are checked to be identifiers before used in the query
+	if _, _, err := validator.LookupTarget(table + "." + column); err != nil {
+		return false, err
+	}
+
+	query := mysqlquery.NewMySqlSelectQueryComposer().
+		Columns("1").
+		PersistenceNames([]string{table}).
+		Where(column+" = ?").
+		Paginate(1, 1).
+		Compose()
+
+	var found int
+	err := l.db.QueryRowContext(ctx, query, value).Scan(&found)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,120 @@
+package sqllookup
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/zibilal/structiterator/validator"
+)
+
+const (
+	success = "✓"
+	failed  = "✗"
+)
+
+// fakeDriver answers every query with one row when the first argument is in rows, recording the last query
+type fakeDriver struct {
+	rows      map[string]bool
+	lastQuery string
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) { return &fakeConn{d}, nil }
+
+type fakeConn struct{ d *fakeDriver }
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return nil, errors.New("not supported")
+}
+func (c *fakeConn) Close() error              { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error) { return nil, errors.New("not supported") }
+
+func (c *fakeConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.d.lastQuery = query
+	if len(args) == 1 && args[0].Value == "broken" {
+		return nil, errors.New("connection refused")
+	}
+	return &fakeRows{found: len(args) == 1 && c.d.rows[fmt.Sprintf("%v", args[0].Value)]}, nil
+}
+
+type fakeRows struct{ found bool }
+
+func (r *fakeRows) Columns() []string { return []string{"1"} }
+func (r *fakeRows) Close() error      { return nil }
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if !r.found {
+		return io.EOF
+	}
+	r.found = false
+	dest[0] = int64(1)
+	return nil
+}
+
+var fake = &fakeDriver{rows: map[string]bool{"jane@example.com": true, "NIS-01": true}}
+
+func init() {
+	sql.Register("sqllookup-fake", fake)
+}
+
+type Registration struct {
+	Email string `json:"email" valid:"funcVal:Unique,format:users.email"`
+	Agent string `json:"agent" valid:"funcVal:Exists,format:agents.nis"`
+}
+
+func TestLookup(t *testing.T) {
+	db, err := sql.Open("sqllookup-fake", "")
+	if err != nil {
+		t.Fatalf("%s expected error nil got %s", failed, err.Error())
+	}
+	defer db.Close()
+
+	validtr := validator.NewValidStruct(validator.NewValidationMapper())
+	validtr.Lookup = New(db)
+
+	t.Log("\nTesting query built with the select builder")
+	{
+		exists, err := validtr.Lookup.Exists(context.Background(), "users", "email", "jane@example.com")
+		expected := "SELECT 1 FROM users WHERE email = ?  LIMIT 1 OFFSET 0"
+		if err == nil && exists && fake.lastQuery == expected {
+			t.Logf("%s expected query %s", success, fake.lastQuery)
+		} else {
+			t.Errorf("%s expected query %s got %s, %v and %v", failed, expected, fake.lastQuery, exists, err)
+		}
+	}
+
+	t.Log("\nTesting Unique and Exists rules")
+	{
+		errs := validtr.Valid(Registration{Email: "jane@example.com", Agent: "NIS-02"})
+		if len(errs) == 2 && errs[0].Error() == "email jane@example.com is already registered" && errs[1].Error() == "agent NIS-02 does not exist" {
+			t.Logf("%s expected errors %v", success, errs)
+		} else {
+			t.Errorf("%s expected errors of email and agent got %v", failed, errs)
+		}
+
+		if errs := validtr.Valid(Registration{Email: "budi@example.com", Agent: "NIS-01"}); len(errs) == 0 {
+			t.Logf("%s expected valid registration", success)
+		} else {
+			t.Errorf("%s expected valid registration got %v", failed, errs)
+		}
+	}
+
+	t.Log("\nTesting failed query and invalid identifier")
+	{
+		errs := validtr.Valid(Registration{Email: "broken"})
+		if len(errs) == 1 && errs[0].Error() == "lookup of users.email failed: connection refused" {
+			t.Logf("%s expected error %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected failed lookup got %v", failed, errs)
+		}
+
+		if _, err := validtr.Lookup.Exists(context.Background(), "users; DROP TABLE users", "email", "x"); err != nil {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected error of invalid table", failed)
+		}
+	}
+}
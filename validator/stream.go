@@ -0,0 +1,157 @@
+package validator
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// StreamRecord is the validation result of one record read by ValidStream.
+// Line and Offset locate the first byte of the record, Line counting from 1 and Offset from 0.
+// Value is a pointer to the decoded record, Errors holds the decoding error or the validation errors.
+type StreamRecord struct {
+	Index  int
+	Line   int
+	Offset int64
+	Value  interface{}
+	Errors []error
+}
+
+// ValidStream reads records from r one at a time and validates each like Valid, calling fn with the result of every record.
+// The input is either a JSON array or a sequence of JSON values, like NDJSON. Every record is decoded into a new value of
+// the struct type of sample, so input of any size is validated in constant memory. A record not matching the struct type
+// is reported in its Errors, a malformed JSON input stops the stream. An error returned by fn stops the stream and is returned.
+func (s *ValidStruct) ValidStream(r io.Reader, sample interface{}, fn func(StreamRecord) error) error {
+	return s.ValidStreamLocale(r, sample, s.DefaultLocale, fn)
+}
+
+// ValidStreamLocale validates records like ValidStream, building error messages from the catalog of locale
+func (s *ValidStruct) ValidStreamLocale(r io.Reader, sample interface{}, locale string, fn func(StreamRecord) error) error {
+	return s.ValidStreamLocaleContext(context.Background(), r, sample, locale, fn)
+}
+
+// ValidStreamContext validates records like ValidStream, passing ctx to the Lookup of funcVal Unique and Exists
+func (s *ValidStruct) ValidStreamContext(ctx context.Context, r io.Reader, sample interface{}, fn func(StreamRecord) error) error {
+	return s.ValidStreamLocaleContext(ctx, r, sample, s.DefaultLocale, fn)
+}
+
+// ValidStreamLocaleContext validates records like ValidStreamLocale, passing ctx to the Lookup of funcVal Unique and Exists.
+// No record is read once ctx is done, the stream stops with the context error.
+func (s *ValidStruct) ValidStreamLocaleContext(ctx context.Context, r io.Reader, sample interface{}, locale string, fn func(StreamRecord) error) error {
+	t, err := sampleStructType(sample)
+	if err != nil {
+		return errors.New("valid stream only accept sample type struct")
+	}
+
+	buffered := bufio.NewReader(r)
+	isArray, err := startsWithArray(buffered)
+	if err != nil {
+		return err
+	}
+
+	lines := &lineCounter{r: buffered, line: 1}
+	dec := json.NewDecoder(lines)
+
+	if isArray {
+		if _, err := dec.Token(); err != nil {
+			return err
+		}
+	}
+
+	for index := 0; ; index++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if isArray && !dec.More() {
+			break
+		}
+
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if err == io.EOF && !isArray {
+				break
+			}
+			return fmt.Errorf("invalid json at offset %d: %s", dec.InputOffset(), err.Error())
+		}
+
+		offset := dec.InputOffset() - int64(len(raw))
+		record := StreamRecord{
+			Index:  index,
+			Line:   lines.lineAt(offset),
+			Offset: offset,
+		}
+
+		value := reflect.New(t)
+		if err := json.Unmarshal(raw, value.Interface()); err != nil {
+			record.Errors = []error{fmt.Errorf("invalid record: %s", err.Error())}
+		} else {
+			record.Errors = s.ValidLocaleContext(ctx, value.Interface(), locale)
+		}
+		record.Value = value.Interface()
+
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+
+	if isArray {
+		if _, err := dec.Token(); err != nil {
+			return fmt.Errorf("invalid json at offset %d: %s", dec.InputOffset(), err.Error())
+		}
+	}
+
+	return nil
+}
+
+// startsWithArray reports whether the first byte other than white space is [
+func startsWithArray(r *bufio.Reader) (bool, error) {
+	for n := 1; ; n++ {
+		peeked, err := r.Peek(n)
+		if len(peeked) < n {
+			if err == io.EOF {
+				return false, nil
+			}
+			return false, err
+		}
+		switch peeked[n-1] {
+		case ' ', '\t', '\r', '\n':
+			continue
+		case '[':
+			return true, nil
+		default:
+			return false, nil
+		}
+	}
+}
+
+// lineCounter remembers where the lines read by the decoder end, so the line of an offset is known.
+// Offsets asked must not decrease, the line ends before them are dropped.
+type lineCounter struct {
+	r        io.Reader
+	read     int64
+	newlines []int64
+	line     int
+}
+
+func (c *lineCounter) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	for i := 0; i < n; i++ {
+		if p[i] == '\n' {
+			c.newlines = append(c.newlines, c.read+int64(i))
+		}
+	}
+	c.read += int64(n)
+	return n, err
+}
+
+func (c *lineCounter) lineAt(offset int64) int {
+	for len(c.newlines) > 0 && c.newlines[0] < offset {
+		c.newlines = c.newlines[1:]
+		c.line++
+	}
+	return c.line
+}
@@ -0,0 +1,117 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+type ImportRow struct {
+	Sku   string `json:"sku" valid:"funcVal:Required"`
+	Email string `json:"email" valid:"funcVal:Email"`
+	Qty   int    `json:"qty" valid:"funcVal:Min,values:1"`
+}
+
+func collectStream(t *testing.T, input string) ([]StreamRecord, error) {
+	validtr := NewValidStruct(NewValidationMapper())
+	var records []StreamRecord
+	err := validtr.ValidStream(strings.NewReader(input), ImportRow{}, func(record StreamRecord) error {
+		records = append(records, record)
+		return nil
+	})
+	return records, err
+}
+
+func checkStreamRecord(t *testing.T, record StreamRecord, line int, offset int64, messages []string) {
+	if record.Line == line && record.Offset == offset {
+		t.Logf("%s expected record %d at line %d offset %d", success, record.Index, line, offset)
+	} else {
+		t.Errorf("%s expected record %d at line %d offset %d got line %d offset %d", failed, record.Index, line, offset, record.Line, record.Offset)
+	}
+	checkErrorMessages(t, record.Errors, messages)
+}
+
+func TestValidStruct_ValidStream(t *testing.T) {
+	t.Log("\nTesting NDJSON input")
+	{
+		input := `{"sku": "A-1", "email": "a@example.com", "qty": 2}
+{"sku": "", "email": "wrong", "qty": 0}
+
+{"sku": "A-3", "qty": "three"}
+`
+		records, err := collectStream(t, input)
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+		if len(records) != 3 {
+			t.Fatalf("%s expected 3 records got %d", failed, len(records))
+		}
+		checkStreamRecord(t, records[0], 1, 0, []string{})
+		checkStreamRecord(t, records[1], 2, 51, []string{"sku is required", "email has invalid format value"})
+		checkStreamRecord(t, records[2], 4, 92, []string{"invalid record: json: cannot unmarshal string into Go struct field ImportRow.qty of type int"})
+
+		if row, ok := records[0].Value.(*ImportRow); ok && row.Sku == "A-1" {
+			t.Logf("%s expected decoded record", success)
+		} else {
+			t.Errorf("%s expected decoded record got %#v", failed, records[0].Value)
+		}
+	}
+
+	t.Log("\nTesting JSON array input")
+	{
+		input := "[\n  {\"sku\": \"A-1\", \"qty\": 1},\n  {\"qty\": -1}\n]"
+		records, err := collectStream(t, input)
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+		if len(records) != 2 {
+			t.Fatalf("%s expected 2 records got %d", failed, len(records))
+		}
+		checkStreamRecord(t, records[0], 2, 4, []string{})
+		checkStreamRecord(t, records[1], 3, 32, []string{"sku is required", "qty must be at least 1"})
+	}
+
+	t.Log("\nTesting malformed input stops the stream")
+	{
+		records, err := collectStream(t, "{\"sku\": \"A-1\"}\n{\"sku\": ")
+		if err != nil && len(records) == 1 {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected an error after 1 record got %v and %d records", failed, err, len(records))
+		}
+	}
+
+	t.Log("\nTesting callback error stops the stream")
+	{
+		validtr := NewValidStruct(NewValidationMapper())
+		stop := errors.New("stop")
+		count := 0
+		err := validtr.ValidStream(strings.NewReader(`{"sku": "1"} {"sku": "2"} {"sku": "3"}`), &ImportRow{}, func(record StreamRecord) error {
+			count++
+			return stop
+		})
+		if err == stop && count == 1 {
+			t.Logf("%s expected the stream to stop", success)
+		} else {
+			t.Errorf("%s expected the stream to stop got %v after %d records", failed, err, count)
+		}
+	}
+
+	t.Log("\nTesting done context stops the stream")
+	{
+		validtr := NewValidStruct(NewValidationMapper())
+		ctx, cancel := context.WithCancel(context.Background())
+		count := 0
+		err := validtr.ValidStreamContext(ctx, strings.NewReader(`[{"sku": "1"}, {"sku": "2"}, {"sku": "3"}]`), ImportRow{}, func(record StreamRecord) error {
+			count++
+			cancel()
+			return nil
+		})
+		if err == context.Canceled && count == 1 {
+			t.Logf("%s expected error %s after 1 record", success, err.Error())
+		} else {
+			t.Errorf("%s expected context canceled after 1 record got %v after %d records", failed, err, count)
+		}
+	}
+}
@@ -0,0 +1,191 @@
+package validator
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+)
+
+// Validator is implemented by a field type validating its own invariants, like Money or PhoneNumber.
+// Valid calls Validate of every field of such type, with or without valid tag. A struct implementing
+// Validator is validated by it only when none of its fields has rules, otherwise it is walked field by field
+// like other nested structs, so its valid tags, message maps, catalogs and registered rules apply, and Validate
+// is not called. The Validate methods written by structvalidgen implement Validator too.
+type Validator interface {
+	Validate() error
+}
+
+// TypeFunc validates a value of the type it is registered for with RegisterTypeFunc
+type TypeFunc func(value interface{}) error
+
+var (
+	validatorType = reflect.TypeOf((*Validator)(nil)).Elem()
+	valuerType    = reflect.TypeOf((*driver.Valuer)(nil)).Elem()
+	scannerType   = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// RegisterTypeFunc makes Valid call fn for every field of the type of sample, or a pointer to it, for types
+// that can not have a Validate method, like types of other packages. fn is used instead of the Validate method of the type.
+func (s *ValidStruct) RegisterTypeFunc(sample interface{}, fn TypeFunc) error {
+	t := reflect.TypeOf(sample)
+	if t == nil || fn == nil {
+		return fmt.Errorf("register type func needs a typed sample and a function")
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	s.typeFuncLock.Lock()
+	if s.typeFuncs == nil {
+		s.typeFuncs = make(map[reflect.Type]TypeFunc)
+	}
+	s.typeFuncs[t] = fn
+	s.typeFuncLock.Unlock()
+
+	return nil
+}
+
+func (s *ValidStruct) typeFunc(t reflect.Type) (TypeFunc, bool) {
+	s.typeFuncLock.RLock()
+	defer s.typeFuncLock.RUnlock()
+
+	fn, found := s.typeFuncs[t]
+	return fn, found
+}
+
+// isTypeValidated reports whether a field of type t is validated as a whole instead of walked,
+// because its type has a type function, or a Validate method or is a driver.Valuer, like sql.NullString, without field rules.
+// A Validator or driver.Valuer struct with field rules is walked like other nested structs.
+func (s *ValidStruct) isTypeValidated(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if _, found := s.typeFunc(t); found {
+		return true
+	}
+	return (implements(t, validatorType) || implements(t, valuerType)) && !s.hasFieldRules(t)
+}
+
+// hasFieldRules reports whether a field of struct type t has rules
+func (s *ValidStruct) hasFieldRules(t reflect.Type) bool {
+	for i := 0; t.Kind() == reflect.Struct && i < t.NumField(); i++ {
+		if s.fieldRules(t, t.Field(i)) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// implements reports whether t or a pointer to t implements iface
+func implements(t reflect.Type, iface reflect.Type) bool {
+	return t.Implements(iface) || reflect.PtrTo(t).Implements(iface)
+}
+
+// validType runs the type function or the Validate method of the type of fv, nothing for a nil pointer
+// or a type walked field by field
+func (s *ValidStruct) validType(fv reflect.Value, path string) []error {
+	value := fv
+	if value.Kind() == reflect.Ptr {
+		if value.IsNil() {
+			return nil
+		}
+		value = value.Elem()
+	}
+	if !value.CanInterface() || !s.isTypeValidated(value.Type()) {
+		return nil
+	}
+
+	if fn, found := s.typeFunc(value.Type()); found {
+		return FieldErrors(path, fn(value.Interface()))
+	}
+
+	if !implements(value.Type(), validatorType) {
+		return nil
+	}
+	if !value.Type().Implements(validatorType) {
+		// Validate has a pointer receiver, call it on an addressable copy when the field is not addressable
+		if !value.CanAddr() {
+			copied := reflect.New(value.Type()).Elem()
+			copied.Set(value)
+			value = copied
+		}
+		value = value.Addr()
+	}
+	return FieldErrors(path, value.Interface().(Validator).Validate())
+}
+
+// FieldErrors turns err returned by the Validate method or type function of the field at path into FieldErrors.
+// Errors is split into its errors, and the Field of a *FieldError is prefixed with path, so the errors of a
+// generated Validate keep their field paths. Any other error becomes a FieldError of path with rule Validate.
+func FieldErrors(path string, err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	switch e := err.(type) {
+	case Errors:
+		var errs []error
+		for _, inner := range e {
+			errs = append(errs, FieldErrors(path, inner)...)
+		}
+		return errs
+	case *FieldError:
+		field := path
+		if path == "" {
+			field = e.Field
+		} else if e.Field != "" {
+			field = path + "." + e.Field
+		}
+		return []error{&FieldError{Field: field, Rule: e.Rule, Err: e.Err}}
+	default:
+		return []error{&FieldError{Field: path, Rule: "Validate", Err: err}}
+	}
+}
+
+// Underlying returns the value held by a driver.Valuer, like the string of a valid sql.NullString or nil of
+// an invalid one, so rules run against it. Other values are returned unchanged.
+func Underlying(value interface{}) interface{} {
+	valuer, ok := value.(driver.Valuer)
+	if !ok {
+		return value
+	}
+	if v := reflect.ValueOf(value); v.Kind() == reflect.Ptr && v.IsNil() {
+		return nil
+	}
+
+	underlying, err := valuer.Value()
+	if err != nil {
+		return nil
+	}
+	return underlying
+}
+
+// underlyingValue returns fv, or the value held by fv when it is a driver.Valuer
+func underlyingValue(fv reflect.Value) reflect.Value {
+	if !fv.IsValid() || !fv.CanInterface() || !implements(fv.Type(), valuerType) {
+		return fv
+	}
+
+	value := fv
+	if !fv.Type().Implements(valuerType) {
+		if !fv.CanAddr() {
+			return fv
+		}
+		value = fv.Addr()
+	}
+
+	underlying := Underlying(value.Interface())
+	if underlying == nil {
+		return reflect.Zero(reflect.TypeOf((*interface{})(nil)).Elem())
+	}
+	return reflect.ValueOf(underlying)
+}
+
+// scanText sets v from text with its sql.Scanner, it reports false when v is not a Scanner
+func scanText(v reflect.Value, text string) (bool, error) {
+	if !v.CanAddr() || !v.Addr().Type().Implements(scannerType) {
+		return false, nil
+	}
+	return true, v.Addr().Interface().(sql.Scanner).Scan(text)
+}
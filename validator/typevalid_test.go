@@ -0,0 +1,235 @@
+package validator
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func (m Money) Validate() error {
+	if m.Amount < 0 {
+		return errors.New("amount can not be negative")
+	}
+	if len(m.Currency) != 3 {
+		return &FieldError{Field: "currency", Rule: "Validate", Err: fmt.Errorf("currency %q is not an ISO 4217 code", m.Currency)}
+	}
+	return nil
+}
+
+type PhoneNumber string
+
+func (p *PhoneNumber) Validate() error {
+	if !strings.HasPrefix(string(*p), "+") {
+		return errors.New("phone number must start with the country code")
+	}
+	return nil
+}
+
+type Sku string
+
+type Invoice struct {
+	Number  string         `json:"number" valid:"funcVal:Required"`
+	Total   Money          `json:"total"`
+	Refund  *Money         `json:"refund"`
+	Phone   PhoneNumber    `json:"phone" valid:"funcVal:Required"`
+	Sku     Sku            `json:"sku"`
+	Note    sql.NullString `json:"note" valid:"funcVal:MinLength,values:5"`
+	Buyer   sql.NullString `json:"buyer" valid:"funcVal:Required"`
+	Copies  sql.NullInt64  `json:"copies" valid:"funcVal:Max,values:3"`
+	Address Address        `json:"address"`
+}
+
+// Address has field rules, so Valid walks it and its Validate is not called
+type Address struct {
+	Street string `json:"street" valid:"funcVal:Required"`
+}
+
+func (a Address) Validate() error {
+	if a.Street == "" {
+		return Errors{&FieldError{Field: "street", Rule: "Required", Err: errors.New("street is required")}}
+	}
+	return nil
+}
+
+// Destination is stored as its city, its fields still have rules
+type Destination struct {
+	City    string `json:"city" valid:"funcVal:Required"`
+	Country string `json:"country" valid:"funcVal:MinLength,values:2"`
+}
+
+func (d Destination) Value() (driver.Value, error) {
+	return d.City, nil
+}
+
+type Shipment struct {
+	Destination Destination `json:"destination"`
+}
+
+func TestTypeValidation(t *testing.T) {
+	validtr := NewValidStruct(NewValidationMapper())
+
+	valid := Invoice{
+		Number:  "INV-1",
+		Total:   Money{Amount: 100, Currency: "IDR"},
+		Phone:   "+6281234567890",
+		Note:    sql.NullString{String: "paid in full", Valid: true},
+		Buyer:   sql.NullString{String: "Jane", Valid: true},
+		Copies:  sql.NullInt64{Int64: 2, Valid: true},
+		Address: Address{Street: "Jl. Sudirman 1"},
+	}
+
+	t.Log("\nTesting a valid struct with types validating themselves")
+	{
+		if errs := validtr.Valid(valid); len(errs) == 0 {
+			t.Logf("%s expected no error", success)
+		} else {
+			t.Errorf("%s expected no error got %v", failed, errs)
+		}
+	}
+
+	t.Log("\nTesting Validate methods of field types")
+	{
+		invoice := valid
+		invoice.Total = Money{Amount: -1, Currency: "IDR"}
+		invoice.Refund = &Money{Amount: 5, Currency: "rupiah"}
+		invoice.Phone = "081234567890"
+		invoice.Address = Address{}
+
+		errs := validtr.Valid(invoice)
+		expected := []string{
+			"total Validate: amount can not be negative",
+			"refund.currency Validate: currency \"rupiah\" is not an ISO 4217 code",
+			"phone Validate: phone number must start with the country code",
+			"address.street Required: street is required",
+		}
+		checkFieldErrors(t, errs, expected)
+	}
+
+	t.Log("\nTesting rules of a driver.Valuer run against its underlying value")
+	{
+		invoice := valid
+		invoice.Note = sql.NullString{String: "paid", Valid: true}
+		invoice.Buyer = sql.NullString{String: "Jane", Valid: false}
+		invoice.Copies = sql.NullInt64{Int64: 5, Valid: true}
+
+		errs := validtr.Valid(invoice)
+		if len(errs) == 3 && errs[0].(*FieldError).Field == "note" && errs[1].(*FieldError).Field == "buyer" && errs[2].(*FieldError).Field == "copies" {
+			t.Logf("%s expected note, buyer and copies errors %v", success, errs)
+		} else {
+			t.Errorf("%s expected note, buyer and copies errors got %v", failed, errs)
+		}
+
+		invoice.Note = sql.NullString{}
+		invoice.Buyer = sql.NullString{String: "Jane", Valid: true}
+		invoice.Copies = sql.NullInt64{}
+		if errs := validtr.Valid(invoice); len(errs) == 0 {
+			t.Logf("%s expected null values to be empty, only Required rejects them", success)
+		} else {
+			t.Errorf("%s expected no error got %v", failed, errs)
+		}
+	}
+
+	t.Log("\nTesting a driver.Valuer struct with field rules is walked")
+	{
+		checkFieldErrors(t, validtr.Valid(Shipment{Destination: Destination{Country: "I"}}), []string{
+			"destination.city Required: city is required",
+			"destination.country MinLength: country length must be at least 2",
+		})
+		checkFieldErrors(t, validtr.Valid(Shipment{Destination: Destination{City: "Yogyakarta", Country: "ID"}}), nil)
+	}
+
+	t.Log("\nTesting a Validator struct with field rules is walked")
+	{
+		invoice := valid
+		invoice.Address = Address{}
+		checkFieldErrors(t, validtr.ValidLocale(invoice, "id"), []string{"address.street Required: street wajib diisi"})
+	}
+
+	t.Log("\nTesting type functions")
+	{
+		skus := NewValidStruct(NewValidationMapper())
+		err := skus.RegisterTypeFunc(Sku(""), func(value interface{}) error {
+			if sku := value.(Sku); sku != "" && !strings.HasPrefix(string(sku), "SKU-") {
+				return fmt.Errorf("sku %s must start with SKU-", sku)
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+
+		invoice := valid
+		invoice.Sku = "A-1"
+		checkFieldErrors(t, skus.Valid(invoice), []string{"sku Validate: sku A-1 must start with SKU-"})
+
+		if errs := validtr.Valid(invoice); len(errs) == 0 {
+			t.Logf("%s expected the type function to belong to its ValidStruct", success)
+		} else {
+			t.Errorf("%s expected no error got %v", failed, errs)
+		}
+
+		if err := skus.RegisterTypeFunc(nil, nil); err != nil {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected error of untyped sample", failed)
+		}
+	}
+
+	t.Log("\nTesting FieldErrors and Underlying")
+	{
+		errs := FieldErrors("items.0", Errors{errors.New("broken"), &FieldError{Field: "sku", Rule: "Required", Err: errors.New("sku is required")}})
+		checkFieldErrors(t, errs, []string{"items.0 Validate: broken", "items.0.sku Required: sku is required"})
+
+		var missing *sql.NullString
+		if Underlying(sql.NullInt64{Int64: 7, Valid: true}) == int64(7) && Underlying(missing) == nil && Underlying("text") == "text" {
+			t.Logf("%s expected underlying values", success)
+		} else {
+			t.Errorf("%s expected underlying values", failed)
+		}
+	}
+
+	t.Log("\nTesting binding a sql.Scanner")
+	{
+		var dst Invoice
+		errs := validtr.BindValues(&dst, url.Values{"note": {"paid later"}, "copies": {"2"}})
+		if len(errs) == 0 && dst.Note.Valid && dst.Note.String == "paid later" && dst.Copies.Int64 == 2 {
+			t.Logf("%s expected note %q and copies %d", success, dst.Note.String, dst.Copies.Int64)
+		} else {
+			t.Errorf("%s expected bound note and copies got %+v and %v", failed, dst, errs)
+		}
+
+		errs = validtr.BindValues(&dst, url.Values{"copies": {"two"}})
+		if len(errs) == 1 && errs[0].Error() == "copies has invalid value two" {
+			t.Logf("%s expected %s", success, errs[0].Error())
+		} else {
+			t.Errorf("%s expected copies has invalid value two got %v", failed, errs)
+		}
+	}
+}
+
+// checkFieldErrors asserts errs are FieldErrors with the field, rule and message of expected
+func checkFieldErrors(t *testing.T, errs []error, expected []string) {
+	var got []string
+	for _, err := range errs {
+		if fieldErr, ok := err.(*FieldError); ok {
+			got = append(got, fmt.Sprintf("%s %s: %s", fieldErr.Field, fieldErr.Rule, fieldErr.Error()))
+		} else {
+			got = append(got, err.Error())
+		}
+	}
+
+	if strings.Join(got, "\n") == strings.Join(expected, "\n") {
+		t.Logf("%s expected errors %q", success, expected)
+	} else {
+		t.Errorf("%s expected errors %q got %q", failed, expected, got)
+	}
+}
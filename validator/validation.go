@@ -3,13 +3,14 @@ package validator
 import (
 	"errors"
 	"fmt"
+	"math"
 	"net/url"
 	"reflect"
-	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 )
 
 type ValidationMapper struct {
@@ -82,8 +83,14 @@ const (
 type Validation struct {
 }
 
+// Required rejects an empty value. A pointer only has to be non nil, so a *bool pointing to false is accepted,
+// NonZero checks the value it points to.
 func (v Validation) Required(value interface{}, key string, defaultError string) error {
-	if IsEmpty(value) {
+	empty := IsEmpty(value)
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Ptr {
+		empty = rv.IsNil()
+	}
+	if empty {
 		if defaultError == "" {
 			return fmt.Errorf("%s is required", key)
 		}
@@ -93,41 +100,79 @@ func (v Validation) Required(value interface{}, key string, defaultError string)
 	}
 }
 
+// NonZero rejects a value that is empty once its pointers are dereferenced, so a nil pointer and a pointer to zero fail
+func (v Validation) NonZero(value interface{}, key string, defaultError string) error {
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+
+	if !rv.IsValid() || rv.Kind() == reflect.Ptr || IsEmpty(rv.Interface()) {
+		if defaultError == "" {
+			return fmt.Errorf("%s must not be zero or empty", key)
+		}
+		return errors.New(defaultError)
+	}
+	return nil
+}
+
+// Present rejects a value that is absent: nil, like a missing key of a map or a json null, or a nil pointer.
+// Unlike Required, a present zero value like 0, false or "" passes.
+func (v Validation) Present(value interface{}, key string, defaultError string) error {
+	rv := reflect.ValueOf(value)
+	if !rv.IsValid() || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		if defaultError == "" {
+			return fmt.Errorf("%s must be present", key)
+		}
+		return errors.New(defaultError)
+	}
+	return nil
+}
+
 func (v Validation) CondRequired(structValue interface{}, key string, zeValue interface{}, keyCompare, valueCompare string, defaultError string) error {
 
 	val := reflect.ValueOf(structValue)
-	typ := val.Type()
 
-	if typ.Kind() != reflect.Struct {
-		return fmt.Errorf("bad value, expected struct value, got ")
+	if val.Kind() != reflect.Struct && !isStringMap(val) {
+		return fmt.Errorf("bad value, expected struct value, got %s", val.Kind())
 	}
 
 	if keyCompare == "" && valueCompare == "" {
 		return errors.New("bad state, keyCompare and valueCompare is expected to have a string value")
 	}
 
-	for i := 0; i < val.NumField(); i++ {
-		fv := val.Field(i)
-		ft := typ.Field(i)
-
-		vkey := ft.Tag.Get("json")
-		if (vkey != "" && vkey == keyCompare) || (vkey == "" && ft.Name == keyCompare) {
-			zVal := fmt.Sprintf("%v", reflect.Indirect(fv))
-			split := strings.Split(valueCompare, "|")
-			for _, sp := range split {
-				if zVal == sp {
-					if IsEmpty(zeValue) {
-						if defaultError == "" {
-							return fmt.Errorf("%s is required", key)
-						} else {
-							return errors.New(defaultError)
-						}
-					}
-				}
+	var compared reflect.Value
+	if val.Kind() == reflect.Map {
+		compared = mapEntry(val, keyCompare)
+	} else {
+		typ := val.Type()
+		for i := 0; i < val.NumField(); i++ {
+			ft := typ.Field(i)
+			vkey := ft.Tag.Get("json")
+			if (vkey != "" && vkey == keyCompare) || (vkey == "" && ft.Name == keyCompare) {
+				compared = val.Field(i)
 			}
 		}
+	}
 
+	if !compared.IsValid() {
+		return nil
 	}
+
+	zVal := fmt.Sprintf("%v", reflect.Indirect(compared))
+	split := strings.Split(valueCompare, "|")
+	for _, sp := range split {
+		if zVal == sp {
+			if IsEmpty(zeValue) {
+				if defaultError == "" {
+					return fmt.Errorf("%s is required", key)
+				} else {
+					return errors.New(defaultError)
+				}
+			}
+		}
+	}
+
 	return nil
 }
 
@@ -179,25 +224,32 @@ func (v Validation) AfterDate(structValue interface{}, key1, key2 string, defaul
 func (v Validation) after(structValue interface{}, key1, key2 string, defaultError string) (reflect.Value, reflect.Value, error) {
 
 	val := reflect.ValueOf(structValue)
-	typ := val.Type()
-
-	if typ.Kind() != reflect.Struct {
-		return reflect.Value{}, reflect.Value{}, fmt.Errorf("bad value, expected struct value, got ")
-	}
 
 	var keyVal1 reflect.Value
 	var keyVal2 reflect.Value
 
-	for i := 0; i < val.NumField(); i++ {
-		fv := val.Field(i)
-		ft := typ.Field(i)
-		vkey := ft.Tag.Get("json")
-
-		if key1 == ft.Name || key1 == vkey {
-			keyVal1 = fv
-		} else if key2 == ft.Name || key2 == vkey {
-			keyVal2 = fv
+	switch {
+	case val.Kind() == reflect.Struct:
+		typ := val.Type()
+		for i := 0; i < val.NumField(); i++ {
+			fv := val.Field(i)
+			ft := typ.Field(i)
+			vkey := ft.Tag.Get("json")
+
+			if key1 == ft.Name || key1 == vkey {
+				keyVal1 = fv
+			} else if key2 == ft.Name || key2 == vkey {
+				keyVal2 = fv
+			}
+		}
+	case isStringMap(val):
+		// an absent entry is compared as empty string
+		keyVal1, keyVal2 = mapEntry(val, key1), mapEntry(val, key2)
+		if !keyVal1.IsValid() {
+			keyVal1 = reflect.ValueOf("")
 		}
+	default:
+		return reflect.Value{}, reflect.Value{}, fmt.Errorf("bad value, expected struct value, got %s", val.Kind())
 	}
 
 	if !keyVal2.IsValid() {
@@ -211,6 +263,20 @@ func (v Validation) after(structValue interface{}, key1, key2 string, defaultErr
 	return keyVal1, keyVal2, nil
 }
 
+// isStringMap reports whether val is a map keyed by string, like a decoded json object
+func isStringMap(val reflect.Value) bool {
+	return val.Kind() == reflect.Map && val.Type().Key().Kind() == reflect.String
+}
+
+// mapEntry returns the value of key in a string keyed map, an absent or nil entry gives an invalid value
+func mapEntry(val reflect.Value, key string) reflect.Value {
+	entry := val.MapIndex(reflect.ValueOf(key).Convert(val.Type().Key()))
+	if entry.IsValid() && entry.Kind() == reflect.Interface {
+		entry = entry.Elem()
+	}
+	return entry
+}
+
 func (v Validation) Email(value interface{}, key, defaultError string) error {
 	if IsEmpty(value) {
 		return nil
@@ -270,15 +336,17 @@ func (v Validation) Date(value interface{}, key, format, layout, defaultError st
 	}
 }
 
+// Match checks a string value matches the regular expression in format. Compiled expressions are cached,
+// expressions and values longer than the limits of SetPatternLimits are rejected.
 func (v Validation) Match(value interface{}, key, format, defaultError string) error {
 
 	if IsEmpty(value) {
 		return nil
 	}
 
-	re, err := regexp.Compile(format)
+	re, err := compilePattern(format)
 	if err != nil {
-		return fmt.Errorf("invalid regular expression %s: %s", format, err.Error())
+		return err
 	}
 	svalue, found := value.(string)
 
@@ -286,6 +354,13 @@ func (v Validation) Match(value interface{}, key, format, defaultError string) e
 		return fmt.Errorf("invalid type, expected string found %s", reflect.TypeOf(value))
 	}
 
+	if _, maxInput := PatternLimits(); len(svalue) > maxInput {
+		if defaultError == "" {
+			return fmt.Errorf("%s is longer than %d bytes", key, maxInput)
+		}
+		return errors.New(defaultError)
+	}
+
 	if !re.MatchString(svalue) {
 		if defaultError == "" {
 			return fmt.Errorf("%s has invalid format value", key)
@@ -307,7 +382,7 @@ func (v Validation) AcceptedValues(value interface{}, key, theValues, defaultErr
 	if strings.Contains(theValues, "<->") {
 		vs = strings.Split(theValues, "<->")
 		isRange = true
-	} else if strings.Contains(theValues, "|") {
+	} else {
 		vs = strings.Split(theValues, "|")
 		isRange = false
 	}
@@ -317,6 +392,140 @@ func (v Validation) AcceptedValues(value interface{}, key, theValues, defaultErr
 	} else {
 		return checkInValues(value, vs, theValues, defaultError)
 	}
+}
+
+// Type checks value has the json type in format, those are string, integer, number, boolean, object and array.
+// A float value without fraction, like a decoded json number, is accepted as integer. A zero value is checked too,
+// only an absent value, nil or a nil pointer, passes.
+func (v Validation) Type(value interface{}, key, format, defaultError string) error {
+	if rv := reflect.ValueOf(value); !rv.IsValid() || (rv.Kind() == reflect.Ptr && rv.IsNil()) {
+		return nil
+	}
+
+	if jsonTypeOf(reflect.ValueOf(value), format) {
+		return nil
+	}
+
+	if defaultError == "" {
+		return fmt.Errorf("%s must be of type %s", key, format)
+	}
+	return errors.New(defaultError)
+}
+
+func jsonTypeOf(val reflect.Value, jsonType string) bool {
+	val = reflect.Indirect(val)
+
+	switch val.Kind() {
+	case reflect.String:
+		return jsonType == "string"
+	case reflect.Bool:
+		return jsonType == "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return jsonType == "integer" || jsonType == "number"
+	case reflect.Float32, reflect.Float64:
+		return jsonType == "number" || (jsonType == "integer" && val.Float() == math.Trunc(val.Float()))
+	case reflect.Map, reflect.Struct:
+		return jsonType == "object"
+	case reflect.Slice, reflect.Array:
+		return jsonType == "array"
+	}
+
+	return false
+}
+
+// Min checks a number value is not less than theValue
+func (v Validation) Min(value interface{}, key, theValue, defaultError string) error {
+	return compareNumber(value, key, theValue, defaultError, false)
+}
+
+// Max checks a number value is not greater than theValue
+func (v Validation) Max(value interface{}, key, theValue, defaultError string) error {
+	return compareNumber(value, key, theValue, defaultError, true)
+}
+
+func compareNumber(value interface{}, key, theValue, defaultError string, isMax bool) error {
+	if IsEmpty(value) {
+		return nil
+	}
+
+	limit, err := strconv.ParseFloat(theValue, 64)
+	if err != nil {
+		return fmt.Errorf("invalid limit %s: %s", theValue, err.Error())
+	}
+
+	var number float64
+	val := reflect.Indirect(reflect.ValueOf(value))
+	switch val.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number = float64(val.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		number = float64(val.Uint())
+	case reflect.Float32, reflect.Float64:
+		number = val.Float()
+	default:
+		return fmt.Errorf("invalid type, expected number found %s", val.Type())
+	}
+
+	if isMax && number > limit {
+		if defaultError == "" {
+			return fmt.Errorf("%s must be at most %s", key, theValue)
+		}
+		return errors.New(defaultError)
+	}
+	if !isMax && number < limit {
+		if defaultError == "" {
+			return fmt.Errorf("%s must be at least %s", key, theValue)
+		}
+		return errors.New(defaultError)
+	}
+
+	return nil
+}
+
+// MinLength checks the length of a string, counted in characters, or of a slice or map is not less than theValue
+func (v Validation) MinLength(value interface{}, key, theValue, defaultError string) error {
+	return compareLength(value, key, theValue, defaultError, false)
+}
+
+// MaxLength checks the length of a string, counted in characters, or of a slice or map is not greater than theValue
+func (v Validation) MaxLength(value interface{}, key, theValue, defaultError string) error {
+	return compareLength(value, key, theValue, defaultError, true)
+}
+
+func compareLength(value interface{}, key, theValue, defaultError string, isMax bool) error {
+	if IsEmpty(value) {
+		return nil
+	}
+
+	limit, err := strconv.Atoi(theValue)
+	if err != nil {
+		return fmt.Errorf("invalid length %s: %s", theValue, err.Error())
+	}
+
+	var length int
+	val := reflect.Indirect(reflect.ValueOf(value))
+	switch val.Kind() {
+	case reflect.String:
+		length = utf8.RuneCountInString(val.String())
+	case reflect.Slice, reflect.Array, reflect.Map:
+		length = val.Len()
+	default:
+		return fmt.Errorf("invalid type, expected string, slice or map found %s", val.Type())
+	}
+
+	if isMax && length > limit {
+		if defaultError == "" {
+			return fmt.Errorf("%s length must be at most %d", key, limit)
+		}
+		return errors.New(defaultError)
+	}
+	if !isMax && length < limit {
+		if defaultError == "" {
+			return fmt.Errorf("%s length must be at least %d", key, limit)
+		}
+		return errors.New(defaultError)
+	}
 
 	return nil
 }
@@ -383,6 +592,15 @@ func checkInValues(val interface{}, vals []string, acceptedValues, errorMessage
 			if ival == v {
 				return nil
 			}
+		case bool:
+			ival := val.(bool)
+			iv, err := strconv.ParseBool(v)
+			if err != nil {
+				return err
+			}
+			if ival == iv {
+				return nil
+			}
 		}
 	}
 
@@ -464,8 +682,25 @@ func checkInRange(val interface{}, val1, val2 string, errorMessage string) error
 				return errors.New(errorMessage)
 			}
 		}
+	case float64:
+		ival := val.(float64)
+		ival1, err := strconv.ParseFloat(val1, 64)
+		if err != nil {
+			return err
+		}
+		ival2, err := strconv.ParseFloat(val2, 64)
+		if err != nil {
+			return err
+		}
+		if !(ival >= ival1 && ival <= ival2) {
+			if errorMessage == "" {
+				return fmt.Errorf("%v is outside of range %v - %v", ival, ival1, ival2)
+			} else {
+				return errors.New(errorMessage)
+			}
+		}
 	default:
-		return errors.New("for check in range only accept int|int64|uint|uint64")
+		return errors.New("for check in range only accept int|int64|uint|uint64|float64")
 	}
 
 	return nil
@@ -1,9 +1,11 @@
 package validator
 
 import (
+	"context"
 	"errors"
 	"reflect"
 	"strings"
+	"sync"
 )
 
 func IsEmpty(val interface{}) bool {
@@ -11,8 +13,8 @@ func IsEmpty(val interface{}) bool {
 
 	if !empty {
 		// check again for empty string
-		if reflect.ValueOf(val).Kind() == reflect.String {
-			if strings.TrimSpace(val.(string)) == "" {
+		if rv := reflect.ValueOf(val); rv.Kind() == reflect.String {
+			if strings.TrimSpace(rv.String()) == "" {
 				empty = true
 			}
 		}
@@ -22,16 +24,44 @@ func IsEmpty(val interface{}) bool {
 }
 
 type ValidStruct struct {
-	mapper          *ValidationMapper
-	PhoneFormat     string
-	EmailFormat     string
-	DateLayout      string
-	DateFormat      string
-	ErrorMessageMap map[string]string
+	mapper           *ValidationMapper
+	PhoneFormat      string
+	EmailFormat      string
+	DateLayout       string
+	DateFormat       string
+	ErrorMessageMap  map[string]string
+	messageLock      sync.RWMutex
+	DefaultLocale    string
+	catalogs         map[string]*MessageCatalog
+	catalogLock      sync.RWMutex
+	modifiers        map[string]Modifier
+	modifierLock     sync.RWMutex
+	typeRules        map[reflect.Type]map[string]registeredRule
+	configRules      map[reflect.Type]map[string]registeredRule
+	namedTypes       map[string]reflect.Type
+	ruleLock         sync.RWMutex
+	schemaHooks      map[string]SchemaHook
+	schemaLock       sync.RWMutex
+	namedPatterns    map[string]string
+	patternLock      sync.RWMutex
+	passwordPolicies map[string]PasswordPolicy
+	passwordLock     sync.RWMutex
+	typeFuncs        map[reflect.Type]TypeFunc
+	typeFuncLock     sync.RWMutex
+	exprs            map[exprKey]compiledExpr
+	exprLock         sync.RWMutex
+	ruleExprs        map[string]parsedRuleExpr
+	ruleExprLock     sync.RWMutex
+
+	// ApplyDefaultsOnValid makes Valid fill empty fields from default tag when input is a pointer to struct
+	ApplyDefaultsOnValid bool
+
+	// Lookup is the data source of funcVal Unique and Exists
+	Lookup Lookup
 }
 
 func NewValidStruct(mapper *ValidationMapper) *ValidStruct {
-	v := ValidStruct{}
+	v := new(ValidStruct)
 	v.PhoneFormat = PhoneFormat
 	v.EmailFormat = EmailFormat
 	v.DateLayout = DateLayout
@@ -39,12 +69,19 @@ func NewValidStruct(mapper *ValidationMapper) *ValidStruct {
 	v.ErrorMessageMap = make(map[string]string)
 	v.mapper = mapper
 	v.setupDefaultMapper()
-	return &v
+	v.modifiers = make(map[string]Modifier)
+	for name, f := range defaultModifiers {
+		v.modifiers[name] = f
+	}
+	for i := range defaultCatalogs {
+		v.AddCatalog(&defaultCatalogs[i])
+	}
+	return v
 }
 
 func NewValidStructWithMap(mapper *ValidationMapper, errorMap map[string]string) *ValidStruct {
 	v := NewValidStruct(mapper)
-	v.ErrorMessageMap = errorMap
+	v.SetErrorMessageMap(errorMap)
 	return v
 }
 
@@ -66,183 +103,320 @@ func (s *ValidStruct) RegisterValidator(name string, f interface{}) error {
 }
 
 func (s *ValidStruct) Valid(input interface{}) []error {
+	return s.ValidLocale(input, s.DefaultLocale)
+}
+
+// ValidLocale validates input like Valid, building error messages from the catalog of locale.
+// When input is a pointer to struct, its fields are normalized by mod tag before validated.
+func (s *ValidStruct) ValidLocale(input interface{}, locale string) []error {
+	return s.ValidLocaleContext(context.Background(), input, locale)
+}
+
+// ValidContext validates input like Valid, passing ctx to the Lookup of funcVal Unique and Exists
+func (s *ValidStruct) ValidContext(ctx context.Context, input interface{}) []error {
+	return s.ValidLocaleContext(ctx, input, s.DefaultLocale)
+}
+
+// ValidLocaleContext validates input like ValidLocale, passing ctx to the Lookup of funcVal Unique and Exists.
+// The lookups of all fields run concurrently once the other rules are run.
+func (s *ValidStruct) ValidLocaleContext(ctx context.Context, input interface{}, locale string) []error {
+	var resultError []error
+
+	if pv := reflect.ValueOf(input); pv.Kind() == reflect.Ptr && !pv.IsNil() && pv.Elem().Kind() == reflect.Struct {
+		resultError = append(resultError, s.prepare(pv.Elem())...)
+	}
+
 	v := reflect.Indirect(reflect.ValueOf(input))
-	t := v.Type()
+	if v.Kind() != reflect.Struct {
+		return []error{errors.New("valid only accept input type struct")}
+	}
+
+	walkFields(v, "", s.isTypeValidated, func(parent, fv reflect.Value, ft reflect.StructField, path string) error {
+		resultError = append(resultError, s.validField(locale, parent, fv, ft, path)...)
+		return nil
+	})
+	resultError = s.resolveLookups(ctx, resultError)
+
+	if len(resultError) > 0 {
+		return resultError
+	} else {
+		return nil
+	}
+}
+
+// validField runs the funcVals in valid tag and registered rules of field ft, parent is the struct holding the field
+// and path the field path reported in FieldError. The funcVals of a driver.Valuer run against its underlying value,
+// and a field type with a registered type function or a Validate method is validated by it after the funcVals.
+func (s *ValidStruct) validField(locale string, parent, fv reflect.Value, ft reflect.StructField, path string) []error {
+	dtags := s.fieldRules(parent.Type(), ft)
+	resultError, omitted := s.runRules(locale, dtags, parent, underlyingValue(fv), parent.Type().Name(), ft.Name, fieldKey(ft), path)
+	if omitted {
+		return nil // neither the funcVals nor the Validate method of the type check a field holding no value
+	}
+
+	return append(resultError, s.validType(fv, path)...)
+}
+
+// fieldKey returns the json name of field ft, or its name when it has no json name
+func fieldKey(ft reflect.StructField) string {
+	if vkey := strings.TrimSpace(strings.Split(ft.Tag.Get("json"), ",")[0]); vkey != "" {
+		return vkey
+	}
+	return ft.Name
+}
+
+// runRules runs the funcVals in dtags against fv. parent is the struct or map holding fv,
+// it is used by funcVals comparing with other fields. typeName and fieldName are used to look up
+// the error message map, keyName is the name of the value in error messages.
+// Every error is a *FieldError of path, except the checks of lookup rules the caller runs with resolveLookups.
+// No funcVal runs when dtags has the omitempty option and fv holds no value, omitted reports it so the caller
+// skips the other checks of the value too.
+func (s *ValidStruct) runRules(locale, dtags string, parent, fv reflect.Value, typeName, fieldName, keyName, path string) ([]error, bool) {
+	if dtags == "" {
+		return nil, false
+	}
+	if IsOmitEmpty(dtags) && isUnsetValue(fv) {
+		return nil, true
+	}
 
 	var resultError []error
 
-	switch t.Kind() {
-	case reflect.Struct:
-		for i := 0; i < t.NumField(); i++ {
-			fv := v.Field(i)
-			ft := t.Field(i)
+	dataTags := []*dataTag{}
+	dataTags = fetchDataTag(dtags, -1, dataTags)
+	sensitive := IsSensitive(dtags)
 
-			if ft.Type.Kind() == reflect.Struct {
-				s.Valid(fv)
-			} else {
-				// process tags
-				dtags := ft.Tag.Get("valid")
-				jsplit := strings.Split(ft.Tag.Get("json"), ",")
-				vkey := ""
-				if len(jsplit) > 0 {
-					vkey = strings.TrimSpace(jsplit[0])
+	for _, dtag := range dataTags {
+		dtag.sensitive = sensitive
+
+		if dtag.funcVal == "Match" {
+			pattern, err := s.resolvePattern(dtag.format)
+			if err != nil {
+				resultError = append(resultError, &FieldError{Field: path, Rule: dtag.funcVal, Err: err})
+				continue
+			}
+			dtag.format = pattern
+		}
+
+		if dtag.expr != "" {
+			if err := s.runExpr(locale, dtag, parent, fv, typeName, fieldName, keyName); err != nil {
+				resultError = append(resultError, &FieldError{Field: path, Rule: "Expr", Err: err})
+			}
+			continue
+		}
+
+		if dtag.funcVal == "Password" {
+			if err := s.checkPassword(locale, parent, fv, typeName, fieldName, keyName, dtag); err != nil {
+				resultError = append(resultError, &FieldError{Field: path, Rule: dtag.funcVal, Err: err})
+			}
+			continue
+		}
+
+		dtag.errorMessage = s.errorMessage(locale, typeName, fieldName, keyName, fv, dtag)
+
+		if IsLookupRule(dtag.funcVal) {
+			if check := newLookupCheck(dtag, fv, keyName, path); check != nil {
+				resultError = append(resultError, check)
+			}
+			continue
+		}
+
+		if dtag.funcVal != "" && IsRuleExpr(dtag.funcVal) {
+			if err := s.runRuleExpr(locale, dtag, parent, fv, typeName, fieldName, keyName); err != nil {
+				resultError = append(resultError, &FieldError{Field: path, Rule: dtag.funcVal, Err: err})
+			}
+			continue
+		}
+
+		if dtag.funcVal != "" {
+			ival, err := s.mapper.GetFunc(dtag.funcVal)
+			if err != nil {
+				resultError = append(resultError, &FieldError{Field: path, Rule: dtag.funcVal, Err: err})
+				continue
+			}
+			if err := s.callFunc(ival, dtag, parent, fv, keyName); err != nil {
+				resultError = append(resultError, &FieldError{Field: path, Rule: dtag.funcVal, Err: err})
+			}
+		}
+	}
+
+	return resultError, false
+}
+
+// callFunc calls ival, the validation function of dtag, with the arguments its signature takes,
+// fv and keyName or parent and the compared keys. A funcVal missing its attributes is skipped.
+func (s *ValidStruct) callFunc(ival interface{}, dtag *dataTag, parent, fv reflect.Value, keyName string) error {
+	val := reflect.ValueOf(ival)
+	if val != (reflect.Value{}) {
+		if val.IsValid() && val.Type().String() == "func(interface {}, string, string) error" {
+			reVal := val.Call([]reflect.Value{
+				fv,
+				reflect.ValueOf(keyName),
+				reflect.ValueOf(dtag.errorMessage),
+			})
+
+			if err := processOutput(reVal[0]); err != nil {
+				return err
+			}
+		} else if val.IsValid() && val.Type().String() == "func(interface {}, string, string, string) error" {
+			k1, k2 := "", ""
+			var theValue reflect.Value
+			if dtag.compareKey != "" && dtag.compareValue != "" {
+				k1, k2 = dtag.compareKey, dtag.compareValue
+				theValue = parent
+			} else if dtag.compareKey != "" && dtag.compareValue == "" {
+				k1, k2 = keyName, dtag.compareKey
+				theValue = parent
+			} else if dtag.acceptedValues != "" {
+				theValue = fv
+				k1 = keyName
+				k2 = dtag.acceptedValues
+			} else if dtag.format != "" {
+				theValue = fv
+				k1 = keyName
+				k2 = dtag.format
+			}
+
+			if k1 != "" && k2 != "" {
+				reVal := val.Call([]reflect.Value{
+					theValue,
+					reflect.ValueOf(k1),
+					reflect.ValueOf(k2),
+					reflect.ValueOf(dtag.errorMessage),
+				})
+
+				if err := processOutput(reVal[0]); err != nil {
+					return err
 				}
+			}
+
+		} else if val.IsValid() && val.Type().String() == "func(interface {}, string, interface {}, string, string, string) error" {
+			k1, k2 := "", ""
+			if dtag.compareKey != "" && dtag.compareValue != "" {
+				k1, k2 = dtag.compareKey, dtag.compareValue
+			}
+
+			if k1 != "" && k2 != "" {
+				reVal := val.Call([]reflect.Value{
+					parent,
+					reflect.ValueOf(keyName),
+					fv,
+					reflect.ValueOf(k1),
+					reflect.ValueOf(k2),
+					reflect.ValueOf(dtag.errorMessage),
+				})
+
+				if err := processOutput(reVal[0]); err != nil {
+					return err
+				}
+			}
+		} else if val.IsValid() && val.Type().String() == "func(interface {}, string, string, string, string) error" {
+			k1, k2 := "", ""
+			if dtag.format != "" && dtag.dateLayout != "" {
+				k1, k2 = dtag.format, dtag.dateLayout
+			} else {
+				k1, k2 = s.DateFormat, s.DateLayout
+			}
 
-				if dtags != "" {
-					dataTags := []*dataTag{}
-					dataTags = fetchDataTag(dtags, -1, dataTags)
-
-					for _, dtag := range dataTags {
-						if len(s.ErrorMessageMap) > 0 && dtag.errorMessage == "" {
-							tmp, found := s.ErrorMessageMap[dtag.funcVal]
-							if found {
-								dtag.errorMessage = tmp
-							}
-						}
-
-						if dtag.funcVal != "" {
-							ival, err := s.mapper.GetFunc(dtag.funcVal)
-							if err != nil {
-								resultError = append(resultError)
-								return resultError
-							}
-							val := reflect.ValueOf(ival)
-							if val != (reflect.Value{}) {
-								if val.IsValid() && val.Type().String() == "func(interface {}, string, string) error" {
-									var keyName string
-									if vkey != "" {
-										keyName = vkey
-									} else {
-										keyName = ft.Name
-									}
-
-									reVal := val.Call([]reflect.Value{
-										fv,
-										reflect.ValueOf(keyName),
-										reflect.ValueOf(dtag.errorMessage),
-									})
-
-									if err := processOutput(reVal[0]); err != nil {
-										resultError = append(resultError, err)
-									}
-								} else if val.IsValid() && val.Type().String() == "func(interface {}, string, string, string) error" {
-									k1, k2 := "", ""
-									var theValue reflect.Value
-									if dtag.compareKey != "" && dtag.compareValue != "" {
-										k1, k2 = dtag.compareKey, dtag.compareValue
-										theValue = v
-									} else if dtag.compareKey != "" && dtag.compareValue == "" {
-										var keyName string
-										if vkey != "" {
-											keyName = vkey
-										} else {
-											keyName = ft.Name
-										}
-										k1, k2 = keyName, dtag.compareKey
-										theValue = v
-									} else if dtag.acceptedValues != "" {
-										theValue = fv
-										if vkey != "" {
-											k1 = vkey
-										} else {
-											k1 = ft.Name
-										}
-										k2 = dtag.acceptedValues
-									} else if dtag.format != "" {
-										theValue = fv
-										if vkey != "" {
-											k1 = vkey
-										} else {
-											k1 = ft.Name
-										}
-										k2 = dtag.format
-									}
-
-									if k1 != "" && k2 != "" {
-										reVal := val.Call([]reflect.Value{
-											theValue,
-											reflect.ValueOf(k1),
-											reflect.ValueOf(k2),
-											reflect.ValueOf(dtag.errorMessage),
-										})
-
-										if err := processOutput(reVal[0]); err != nil {
-											resultError = append(resultError, err)
-										}
-									}
-
-								} else if val.IsValid() && val.Type().String() == "func(interface {}, string, interface {}, string, string, string) error" {
-									k1, k2 := "", ""
-									if dtag.compareKey != "" && dtag.compareValue != "" {
-										k1, k2 = dtag.compareKey, dtag.compareValue
-									}
-
-									if k1 != "" && k2 != "" {
-										var keyName string
-										if vkey != "" {
-											keyName = vkey
-										} else {
-											keyName = ft.Name
-										}
-
-										reVal := val.Call([]reflect.Value{
-											v,
-											reflect.ValueOf(keyName),
-											fv,
-											reflect.ValueOf(k1),
-											reflect.ValueOf(k2),
-											reflect.ValueOf(dtag.errorMessage),
-										})
-
-										if err := processOutput(reVal[0]); err != nil {
-											resultError = append(resultError, err)
-										}
-									}
-								} else if val.IsValid() && val.Type().String() == "func(interface {}, string, string, string, string) error" {
-									k1, k2 := "", ""
-									if dtag.format != "" && dtag.dateLayout != "" {
-										k1, k2 = dtag.format, dtag.dateLayout
-									} else {
-										k1, k2 = s.DateFormat, s.DateLayout
-									}
-
-									if k1 != "" && k2 != "" {
-										var keyName string
-										if vkey != "" {
-											keyName = vkey
-										} else {
-											keyName = ft.Name
-										}
-
-										reVal := val.Call([]reflect.Value{
-											fv,
-											reflect.ValueOf(keyName),
-											reflect.ValueOf(k1),
-											reflect.ValueOf(k2),
-											reflect.ValueOf(dtag.errorMessage),
-										})
-										if err := processOutput(reVal[0]); err != nil {
-											resultError = append(resultError, err)
-										}
-									}
-								}
-							}
-
-						}
-					}
+			if k1 != "" && k2 != "" {
+				reVal := val.Call([]reflect.Value{
+					fv,
+					reflect.ValueOf(keyName),
+					reflect.ValueOf(k1),
+					reflect.ValueOf(k2),
+					reflect.ValueOf(dtag.errorMessage),
+				})
+				if err := processOutput(reVal[0]); err != nil {
+					return err
 				}
 			}
 		}
+	}
 
-		if len(resultError) > 0 {
-			return resultError
-		} else {
-			return nil
+	return nil
+}
+
+// prepare fills defaults, when ApplyDefaultsOnValid is set, and normalizes the fields of v before it is validated
+func (s *ValidStruct) prepare(v reflect.Value) []error {
+	var resultError []error
+	if s.ApplyDefaultsOnValid {
+		if err := s.applyDefaults(v); err != nil {
+			resultError = append(resultError, err)
+		}
+	}
+	if err := s.normalize(v); err != nil {
+		resultError = append(resultError, err)
+	}
+	return resultError
+}
+
+// walkStruct calls fn for every field of struct v. Nested struct fields are walked instead of passed to fn,
+// while a pointer to struct field is passed to fn and then walked when it is not nil.
+// parent is the struct holding the field. Walking stops at the first error returned by fn.
+func walkStruct(v reflect.Value, fn func(parent, fv reflect.Value, ft reflect.StructField) error) error {
+	return walkStructPath(v, "", func(parent, fv reflect.Value, ft reflect.StructField, path string) error {
+		return fn(parent, fv, ft)
+	})
+}
+
+// walkStructPath walks v like walkStruct, also passing the path of each field, the keys of the fields
+// from v joined by dot. An embedded struct adds no key to the path, like encoding/json flattens it.
+func walkStructPath(v reflect.Value, prefix string, fn func(parent, fv reflect.Value, ft reflect.StructField, path string) error) error {
+	return walkFields(v, prefix, nil, fn)
+}
+
+// walkFields walks v like walkStructPath, a field whose type is a leaf, when leaf is not nil, is passed to fn
+// and not walked even when it is a struct or a pointer to struct
+func walkFields(v reflect.Value, prefix string, leaf func(t reflect.Type) bool, fn func(parent, fv reflect.Value, ft reflect.StructField, path string) error) error {
+	t := v.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		fv := v.Field(i)
+		ft := t.Field(i)
+
+		if ft.PkgPath != "" && !ft.Anonymous {
+			continue // unexported field
 		}
 
-	default:
-		return []error{errors.New("valid only accept input type struct")}
+		path := prefix + fieldKey(ft)
+		nestedPrefix := path + "."
+		if ft.Anonymous {
+			nestedPrefix = prefix
+		}
+
+		if leaf != nil && leaf(ft.Type) {
+			if ft.Anonymous {
+				path = strings.TrimSuffix(nestedPrefix, ".") // an embedded type adds no key, its errors belong to v
+			}
+			if err := fn(v, fv, ft, path); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if isNestedStruct(ft.Type) {
+			if err := walkFields(fv, nestedPrefix, leaf, fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := fn(v, fv, ft, path); err != nil {
+			return err
+		}
+
+		if ft.Type.Kind() == reflect.Ptr && isNestedStruct(ft.Type.Elem()) && !fv.IsNil() {
+			if err := walkFields(fv.Elem(), nestedPrefix, leaf, fn); err != nil {
+				return err
+			}
+		}
 	}
+
+	return nil
+}
+
+// isNestedStruct reports whether t is a struct walked field by field, time.Time is treated as a value
+func isNestedStruct(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && t != timeType
 }
 
 func processOutput(reVal reflect.Value) error {
@@ -266,6 +440,76 @@ type dataTag struct {
 	compareValue   string
 	dateLayout     string
 	acceptedValues string
+	// expr is the expression of an expr rule, a rule without funcVal
+	expr string
+	// sensitive is set for the sensitive option, and by runRules on every rule of a sensitive field
+	sensitive bool
+	// omitEmpty is set for the omitempty option
+	omitEmpty bool
+}
+
+// SplitTag splits tag, in valid tag syntax, at sep: ';' between rules or ',' between the options of a rule.
+// A sep inside a quoted string of an expr or funcVal option is kept, like in expr:Name == 'a,b', and so is a sep
+// in the value of another option written in quotes, like format:'^[a-z]{2,4}$', see QuoteTagValue.
+// The other quotes, like an apostrophe in errorMessage, are plain characters.
+func SplitTag(tag string, sep byte) []string {
+	const (
+		unquoted = iota
+		exprQuoted
+		valueQuoted
+	)
+
+	var parts []string
+	start, optionStart, quote := 0, 0, unquoted
+	for i := 0; i < len(tag); i++ {
+		c := tag[i]
+		switch {
+		case c == '\'' && quote == exprQuoted:
+			quote = unquoted
+		case c == '\'' && quote == valueQuoted:
+			if i+1 < len(tag) && tag[i+1] == '\'' {
+				i++ // a doubled quote is a quote of the value
+			} else {
+				quote = unquoted
+			}
+		case quote != unquoted:
+		case c == '\'':
+			option := strings.TrimLeft(tag[optionStart:i], " ")
+			if strings.HasPrefix(option, "expr:") || strings.HasPrefix(option, "funcVal:") {
+				quote = exprQuoted
+			} else if option != "" && strings.IndexByte(option, ':') == len(option)-1 {
+				quote = valueQuoted // the quote starts the value
+			}
+		case c == ',' || c == ';':
+			optionStart = i + 1
+			if c == sep {
+				parts = append(parts, tag[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(parts, tag[start:])
+}
+
+// QuoteTagValue returns value ready to be written as the value of an option of valid tag, other than expr and funcVal.
+// A value holding ',' or ';', or starting with a quote, is written in quotes with its quotes doubled.
+func QuoteTagValue(value string) string {
+	if !strings.ContainsAny(value, ",;") && !strings.HasPrefix(value, "'") {
+		return value
+	}
+	return "'" + strings.Replace(value, "'", "''", -1) + "'"
+}
+
+// unquoteTagValue returns the value written by QuoteTagValue, a value not quoted by it is returned as it is
+func unquoteTagValue(value string) string {
+	if len(value) < 2 || value[0] != '\'' || value[len(value)-1] != '\'' {
+		return value
+	}
+	inner := value[1 : len(value)-1]
+	if strings.Contains(strings.Replace(inner, "''", "", -1), "'") {
+		return value
+	}
+	return strings.Replace(inner, "''", "'", -1)
 }
 
 // fetchDataTag idx must always starts from -1
@@ -273,7 +517,7 @@ func fetchDataTag(input string, idx int, dataTags []*dataTag) []*dataTag {
 	if input == "" {
 		return dataTags
 	}
-	tagsSplits := strings.Split(input, ";")
+	tagsSplits := SplitTag(input, ';')
 	if len(tagsSplits) > 1 {
 		for i := 0; i < len(tagsSplits); i++ {
 			dataTags = append(dataTags, &dataTag{})
@@ -282,7 +526,7 @@ func fetchDataTag(input string, idx int, dataTags []*dataTag) []*dataTag {
 			fetchDataTag(tagsSplits[i], i, dataTags)
 		}
 	} else {
-		atagSplits := strings.Split(input, ",")
+		atagSplits := SplitTag(input, ',')
 		if len(atagSplits) > 1 {
 			if len(dataTags) == 0 {
 				dataTags = append(dataTags, &dataTag{})
@@ -292,7 +536,7 @@ func fetchDataTag(input string, idx int, dataTags []*dataTag) []*dataTag {
 				fetchDataTag(atagSplits[i], idx, dataTags)
 			}
 		} else {
-			splits := strings.Split(input, ":")
+			splits := strings.SplitN(input, ":", 2)
 			if len(dataTags) == 0 {
 				dataTags = append(dataTags, &dataTag{})
 				idx = 0
@@ -307,19 +551,25 @@ func fetchDataTag(input string, idx int, dataTags []*dataTag) []*dataTag {
 				case "funcVal":
 					itag.funcVal = splits[1]
 				case "errorMessage":
-					itag.errorMessage = splits[1]
+					itag.errorMessage = unquoteTagValue(splits[1])
 				case "format":
-					itag.format = splits[1]
+					itag.format = unquoteTagValue(splits[1])
 				case "compareValue":
-					itag.compareValue = splits[1]
+					itag.compareValue = unquoteTagValue(splits[1])
 				case "compareKey":
-					itag.compareKey = splits[1]
+					itag.compareKey = unquoteTagValue(splits[1])
 				case "dateLayout":
-					itag.dateLayout = splits[1]
+					itag.dateLayout = unquoteTagValue(splits[1])
 				case "values":
-					itag.acceptedValues = splits[1]
+					itag.acceptedValues = unquoteTagValue(splits[1])
+				case "expr":
+					itag.expr = splits[1]
 				}
 				fetchDataTag("", idx, dataTags)
+			} else if strings.TrimSpace(splits[0]) == SensitiveOption {
+				dataTags[idx].sensitive = true
+			} else if strings.TrimSpace(splits[0]) == OmitEmptyOption {
+				dataTags[idx].omitEmpty = true
 			}
 		}
 	}
@@ -262,3 +262,23 @@ func TestDataTag(t *testing.T) {
 		t.Log("ends")
 	}
 }
+
+type Order struct {
+	Id       uint `valid:"funcVal:Required"`
+	Customer Person
+	Shipping *Person
+}
+
+func TestNestedStruct(t *testing.T) {
+	mapper := NewValidationMapper()
+	t.Log("\nTesting nested struct errors are reported")
+	{
+		order := Order{Id: 1, Shipping: &Person{Name: "Bilal"}}
+		validtr := NewValidStruct(mapper)
+		checkFieldErrors(t, validtr.Valid(order), []string{
+			"Customer.Name Required: Name is required",
+			"Customer.Email Required: Email is required",
+			"Shipping.Email Required: Email is required",
+		})
+	}
+}
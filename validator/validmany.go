@@ -0,0 +1,112 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrTooManyErrors is returned by ValidMany when the errors found reach ManyOptions.MaxErrors
+var ErrTooManyErrors = errors.New("validation stopped, too many errors")
+
+// ManyOptions controls ValidMany
+type ManyOptions struct {
+	// Context stops the validation when it is done and is passed to the Lookup of funcVal Unique and Exists,
+	// nil means context.Background
+	Context context.Context
+	// Workers is the number of elements validated at the same time, below 1 means runtime.GOMAXPROCS
+	Workers int
+	// MaxErrors stops the validation once the errors of all elements reach it, 0 means no limit.
+	// The limit is approximate: elements already being validated when it is reached still finish and add their errors.
+	MaxErrors int
+	// Locale of error messages, empty means DefaultLocale
+	Locale string
+}
+
+// ManyResult is the validation result of the element at Index.
+// Validated is false when the validation stopped before reaching the element.
+type ManyResult struct {
+	Index     int
+	Errors    []error
+	Validated bool
+}
+
+// ValidMany validates each element of slice, a slice of struct or pointer to struct, like Valid in a pool of workers.
+// The results are in the order of the elements. When the context is done or MaxErrors is reached, the elements
+// not yet started are left unvalidated and the context error or ErrTooManyErrors is returned with the results.
+func (s *ValidStruct) ValidMany(slice interface{}, opts ManyOptions) ([]ManyResult, error) {
+	v := reflect.ValueOf(slice)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, errors.New("valid many only accept input type slice")
+	}
+
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	locale := opts.Locale
+	if locale == "" {
+		locale = s.DefaultLocale
+	}
+	workers := opts.Workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	if workers > v.Len() {
+		workers = v.Len()
+	}
+
+	results := make([]ManyResult, v.Len())
+	for i := range results {
+		results[i].Index = i
+	}
+
+	var (
+		errorCount int64
+		capped     int32
+		wg         sync.WaitGroup
+	)
+	jobs := make(chan int)
+
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i].Errors = s.ValidLocaleContext(ctx, v.Index(i).Interface(), locale)
+				results[i].Validated = true
+
+				if opts.MaxErrors > 0 && atomic.AddInt64(&errorCount, int64(len(results[i].Errors))) >= int64(opts.MaxErrors) {
+					atomic.StoreInt32(&capped, 1)
+				}
+			}
+		}()
+	}
+
+	fed := 0
+feed:
+	for ; fed < v.Len(); fed++ {
+		if atomic.LoadInt32(&capped) == 1 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			break feed
+		case jobs <- fed:
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	if fed < v.Len() {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		return results, ErrTooManyErrors
+	}
+
+	return results, nil
+}
@@ -0,0 +1,90 @@
+package validator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+)
+
+func TestValidStruct_ValidMany(t *testing.T) {
+	validtr := NewValidStruct(NewValidationMapper())
+
+	people := make([]Person, 1000)
+	for i := range people {
+		people[i] = Person{Name: fmt.Sprintf("person %d", i), Email: fmt.Sprintf("person%d@example.com", i)}
+	}
+	people[10].Email = "wrong"
+	people[500].Name = ""
+
+	t.Log("\nTesting results are in element order")
+	{
+		results, err := validtr.ValidMany(people, ManyOptions{Workers: 8})
+		if err != nil {
+			t.Fatalf("%s expected error nil got %s", failed, err.Error())
+		}
+		failedIndexes := []int{}
+		for i, result := range results {
+			if result.Index != i || !result.Validated {
+				t.Fatalf("%s expected result %d validated got %+v", failed, i, result)
+			}
+			if len(result.Errors) > 0 {
+				failedIndexes = append(failedIndexes, i)
+			}
+		}
+		if fmt.Sprint(failedIndexes) == "[10 500]" {
+			t.Logf("%s expected elements 10 and 500 to fail", success)
+		} else {
+			t.Errorf("%s expected elements 10 and 500 to fail got %v", failed, failedIndexes)
+		}
+		checkErrorMessages(t, results[500].Errors, []string{"Name is required"})
+	}
+
+	t.Log("\nTesting pointer elements and locale")
+	{
+		results, _ := validtr.ValidMany([]*Person{{Name: "A", Email: "a@example.com"}, {}}, ManyOptions{Locale: "id"})
+		checkErrorMessages(t, results[1].Errors, []string{"Name wajib diisi", "Email wajib diisi"})
+	}
+
+	t.Log("\nTesting error cap stops the validation")
+	{
+		invalid := make([]Person, 1000)
+		results, err := validtr.ValidMany(invalid, ManyOptions{Workers: 2, MaxErrors: 10})
+		if err == ErrTooManyErrors {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected error %s got %v", failed, ErrTooManyErrors, err)
+		}
+		if results[len(results)-1].Validated {
+			t.Errorf("%s expected the last element left unvalidated", failed)
+		} else {
+			t.Logf("%s expected the last element left unvalidated", success)
+		}
+	}
+
+	t.Log("\nTesting cancelled context")
+	{
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		results, err := validtr.ValidMany(people, ManyOptions{Context: ctx})
+		validated := 0
+		for _, result := range results {
+			if result.Validated {
+				validated++
+			}
+		}
+		if err == context.Canceled && validated < len(people) {
+			t.Logf("%s expected error %s, %d elements validated", success, err.Error(), validated)
+		} else {
+			t.Errorf("%s expected error %s got %v, %d elements validated", failed, context.Canceled, err, validated)
+		}
+	}
+
+	t.Log("\nTesting input other than slice")
+	{
+		if _, err := validtr.ValidMany(Person{}, ManyOptions{}); err != nil {
+			t.Logf("%s expected error %s", success, err.Error())
+		} else {
+			t.Errorf("%s expected error valid many only accept input type slice", failed)
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package validator
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var interfaceType = reflect.TypeOf((*interface{})(nil)).Elem()
+
+// mapEntryValue is a value found in a map payload by a dotted key
+type mapEntryValue struct {
+	parent reflect.Value
+	value  reflect.Value
+	path   string
+}
+
+// ValidMap validates data, like a decoded json object, against rules. rules is keyed by the path of the value
+// and holds the same syntax as valid tag. A path separates nested keys by dot, a slice element is selected
+// by its index or by * for every element, like address.city or items.*.sku.
+func (s *ValidStruct) ValidMap(data map[string]interface{}, rules map[string]string) []error {
+	return s.ValidMapLocale(data, rules, s.DefaultLocale)
+}
+
+// ValidMapLocale validates data like ValidMap, building error messages from the catalog of locale
+func (s *ValidStruct) ValidMapLocale(data map[string]interface{}, rules map[string]string, locale string) []error {
+	return s.ValidMapLocaleContext(context.Background(), data, rules, locale)
+}
+
+// ValidMapContext validates data like ValidMap, passing ctx to the Lookup of funcVal Unique and Exists
+func (s *ValidStruct) ValidMapContext(ctx context.Context, data map[string]interface{}, rules map[string]string) []error {
+	return s.ValidMapLocaleContext(ctx, data, rules, s.DefaultLocale)
+}
+
+// ValidMapLocaleContext validates data like ValidMapLocale, passing ctx to the Lookup of funcVal Unique and Exists
+func (s *ValidStruct) ValidMapLocaleContext(ctx context.Context, data map[string]interface{}, rules map[string]string, locale string) []error {
+	if data == nil {
+		return []error{errors.New("valid map only accept non nil map")}
+	}
+
+	keys := make([]string, 0, len(rules))
+	for key := range rules {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var resultError []error
+	for _, key := range keys {
+		if rules[key] == "" {
+			continue
+		}
+
+		for _, entry := range findMapEntries(data, strings.Split(key, "."), "") {
+			errs, _ := s.runRules(locale, rules[key], entry.parent, entry.value, "", key, entry.path, entry.path)
+			resultError = append(resultError, errs...)
+		}
+	}
+	resultError = s.resolveLookups(ctx, resultError)
+
+	if len(resultError) > 0 {
+		return resultError
+	}
+	return nil
+}
+
+// findMapEntries resolves the path parts against current. A missing intermediate map resolves
+// into an absent value, so Required still reports it, while a * on a missing slice resolves to nothing.
+func findMapEntries(current interface{}, parts []string, prefix string) []mapEntryValue {
+	part := parts[0]
+
+	if part == "*" {
+		slice := reflect.ValueOf(current)
+		if slice.Kind() != reflect.Slice && slice.Kind() != reflect.Array {
+			return nil
+		}
+
+		var entries []mapEntryValue
+		for i := 0; i < slice.Len(); i++ {
+			entries = append(entries, findMapIndex(slice, i, parts, prefix)...)
+		}
+		return entries
+	}
+
+	if idx, err := strconv.Atoi(part); err == nil {
+		slice := reflect.ValueOf(current)
+		if slice.Kind() == reflect.Slice || slice.Kind() == reflect.Array {
+			if idx < 0 || idx >= slice.Len() {
+				return findMapEntries(nil, parts[1:], prefix+part+".")
+			}
+			return findMapIndex(slice, idx, parts, prefix)
+		}
+	}
+
+	parent := reflect.ValueOf(current)
+	if !isStringMap(parent) {
+		parent = reflect.ValueOf(map[string]interface{}{})
+	}
+
+	entry := mapEntry(parent, part)
+	if len(parts) > 1 {
+		var next interface{}
+		if entry.IsValid() {
+			next = entry.Interface()
+		}
+		return findMapEntries(next, parts[1:], prefix+part+".")
+	}
+
+	if !entry.IsValid() {
+		entry = reflect.Zero(interfaceType)
+	}
+
+	return []mapEntryValue{{parent: parent, value: entry, path: prefix + part}}
+}
+
+func findMapIndex(slice reflect.Value, idx int, parts []string, prefix string) []mapEntryValue {
+	path := prefix + strconv.Itoa(idx)
+	elem := slice.Index(idx)
+
+	if len(parts) > 1 {
+		return findMapEntries(elem.Interface(), parts[1:], path+".")
+	}
+
+	if elem.Kind() == reflect.Interface {
+		if elem.IsNil() {
+			elem = reflect.Zero(interfaceType)
+		} else {
+			elem = elem.Elem()
+		}
+	}
+
+	return []mapEntryValue{{parent: slice, value: elem, path: path}}
+}
@@ -0,0 +1,65 @@
+package validator
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const webhookPayload = `{
+	"event": "order.paid",
+	"status": "approved",
+	"contact": {"email": "buyer@example", "phone": "081234567"},
+	"applied_time": "09/20/2017",
+	"approved_time": "09/19/2017",
+	"amount": 150000,
+	"items": [
+		{"sku": "VV-112-11234", "qty": 2},
+		{"sku": "", "qty": 120}
+	]
+}`
+
+func TestValidStruct_ValidMap(t *testing.T) {
+	data := map[string]interface{}{}
+	if err := json.Unmarshal([]byte(webhookPayload), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Log("\nTesting valid map payload")
+	{
+		validtr := NewValidStruct(NewValidationMapper())
+		errs := validtr.ValidMap(data, map[string]string{
+			"event":         "funcVal:Required;funcVal:AcceptedValues,values:order.paid|order.refunded",
+			"reason":        "funcVal:CondRequired,compareKey:status,compareValue:approved|rejected",
+			"contact.email": "funcVal:Required;funcVal:Email",
+			"contact.phone": "funcVal:Phone",
+			"approved_time": "funcVal:AfterDate,compareKey:applied_time",
+			"amount":        "funcVal:AcceptedValues,values:1000<->100000",
+			"items.*.sku":   "funcVal:Required",
+			"items.*.qty":   "funcVal:AcceptedValues,values:1<->100",
+			"shipping.city": "funcVal:Required",
+		})
+
+		expected := []string{
+			"150000 is outside of range 1000 - 100000",
+			"invalid approved_time should be after applied_time",
+			"contact.email has invalid format value",
+			"120 is outside of range 1 - 100",
+			"items.1.sku is required",
+			"reason is required",
+			"shipping.city is required",
+		}
+		checkErrorMessages(t, errs, expected)
+	}
+
+	t.Log("\nTesting valid map with error message map and locale")
+	{
+		validtr := NewValidStruct(NewValidationMapper())
+		validtr.SetErrorMessage("items.*.sku.Required", "sku of item {field} is required")
+		errs := validtr.ValidMapLocale(data, map[string]string{
+			"items.*.sku":   "funcVal:Required",
+			"shipping.city": "funcVal:Required",
+		}, "id")
+
+		checkErrorMessages(t, errs, []string{"sku of item items.1.sku is required", "shipping.city wajib diisi"})
+	}
+}